@@ -0,0 +1,9 @@
+data "aci_faults" "tenant" {
+  dn       = "uni/tn-EXAMPLE_TENANT"
+  severity = "major"
+}
+
+data "aci_faults" "epgs" {
+  class_name = "fvAEPg"
+  code       = "F0467"
+}
@@ -0,0 +1,10 @@
+data "aci_health" "fabric" {
+  dn = "topology/health"
+
+  lifecycle {
+    postcondition {
+      condition     = self.current >= 90
+      error_message = "Fabric health score is below 90."
+    }
+  }
+}
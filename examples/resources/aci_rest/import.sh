@@ -1 +1,13 @@
-terraform import aci_rest.fvTenant fvTenant:uni/tn-EXAMPLE_TENANT 
\ No newline at end of file
+terraform import aci_rest.fvTenant fvTenant:uni/tn-EXAMPLE_TENANT
+
+# The class name can be omitted, in which case it is discovered by reading the object.
+terraform import aci_rest.fvTenant uni/tn-EXAMPLE_TENANT
+
+# Distinguished names may contain colons, e.g. of MAC addresses.
+terraform import aci_rest.fvStCEp "fvStCEp:uni/tn-EXAMPLE_TENANT/ap-AP1/epg-EPG1/stcep-00:50:56:AA:BB:CC-type-silent-host"
+
+# Optionally import children of the given classes and only track the listed attributes,
+# where attributes prefixed with a child class name apply to the respective children.
+# Without a list of attributes, no attributes are tracked in content until the next update and
+# the configured attributes are compared to the attributes of the object.
+terraform import aci_rest.fvTenant "fvTenant:uni/tn-EXAMPLE_TENANT:fvCtx,fvBD:name,fvCtx.name,fvBD.name"
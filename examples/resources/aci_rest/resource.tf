@@ -30,3 +30,62 @@ resource "aci_rest" "fvTenant" {
     }
   }
 }
+
+resource "aci_rest" "fvTenant" {
+  dn         = "uni/tn-EXAMPLE_TENANT"
+  class_name = "fvTenant"
+  content = {
+    name = "EXAMPLE_TENANT"
+  }
+
+  children = {
+    "ctx-VRF1" = jsonencode({
+      class_name = "fvCtx"
+      content = {
+        name = "VRF1"
+      }
+    })
+  }
+}
+
+resource "aci_rest" "aaaUser" {
+  dn         = "uni/userext/user-EXAMPLE_USER"
+  class_name = "aaaUser"
+  content = {
+    name = "EXAMPLE_USER"
+  }
+  sensitive_content = {
+    pwd = var.password
+  }
+}
+
+resource "aci_rest" "fvAEPg" {
+  dn         = "uni/tn-EXAMPLE_TENANT/ap-AP1/epg-EPG1"
+  class_name = "fvAEPg"
+  content = {
+    name = "EPG1"
+  }
+
+  fault_check {
+    severity = "major"
+    timeout  = 30
+  }
+}
+
+resource "aci_rest" "fabricNodeIdentP" {
+  dn         = "uni/controller/nodeidentpol/nodep-FDO12345678"
+  class_name = "fabricNodeIdentP"
+  content = {
+    serial = "FDO12345678"
+    nodeId = "101"
+    name   = "LEAF101"
+  }
+
+  wait_for {
+    dn = "topology/pod-1/node-101"
+    attributes = {
+      fabricSt = "active"
+    }
+    timeout = 900
+  }
+}
@@ -10,11 +10,35 @@ const MaxDelay = 60 * time.Second
 // List of attributes to be not stored in state
 var IgnoreAttr = []string{"extMngdBy", "lcOwn", "modTs", "monPolDn", "uid", "dn", "rn", "configQual", "configSt", "virtualIp", "annotation"}
 
-// List of attributes to be only written to state from config
-var WriteOnlyAttr = []string{"childAction"}
+// List of attributes to be only written to state from config, as the APIC does not return the written values
+var WriteOnlyAttr = []string{"childAction", "status"}
 
 // List of classes where 'rsp-prop-include=config-only' does not return the desired objects/properties
 var FullClasses = []string{"firmwareFwGrp", "maintMaintGrp", "maintMaintP", "firmwareFwP"}
 
 // List of classes which do not support annotations
 var NoAnnotationClasses = []string{"tagTag"}
+
+// Maximum number of children for which reads are filtered by distinguished name
+const MaxChildrenFilter = 20
+
+// Placeholder for unknown values passed to validation functions
+const UnknownValue = "74D93920-ED26-11E3-AC10-0800200C9A66"
+
+// Fault severities in ascending order
+var FaultSeverities = []string{"cleared", "info", "warning", "minor", "major", "critical"}
+
+// Interval between queries when checking for faults after writing an object
+const FaultCheckInterval = 2 * time.Second
+
+// Interval between queries when waiting for conditions after writing an object
+const WaitForInterval = 5 * time.Second
+
+// Default timeout of create, read, update and delete operations
+const DefaultTimeout = 20 * time.Minute
+
+// Timeout of the reachability check of each controller when configuring the provider
+const HealthCheckTimeout = 10 * time.Second
+
+// Timeout of the credential command when configuring the provider
+const CredentialCommandTimeout = 60 * time.Second
@@ -0,0 +1,131 @@
+package provider
+
+import (
+	"context"
+	"net/url"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+func dataSourceAciFaults() *schema.Resource {
+	return &schema.Resource{
+		Description: "This data source can read the faults of an ACI object and its children or of all objects of a class.",
+
+		ReadContext: dataSourceAciFaultsRead,
+
+		Timeouts: &schema.ResourceTimeout{
+			Read: schema.DefaultTimeout(DefaultTimeout),
+		},
+
+		Schema: map[string]*schema.Schema{
+			"id": {
+				Description: "The distinguished name or class name of the queried objects.",
+				Type:        schema.TypeString,
+				Computed:    true,
+			},
+			"dn": {
+				Type:         schema.TypeString,
+				Description:  "Distinguished name of object whose faults and the faults of its children are retrieved, e.g. uni/tn-EXAMPLE_TENANT.",
+				Optional:     true,
+				ValidateFunc: validateDn,
+				ExactlyOneOf: []string{"dn", "class_name"},
+			},
+			"class_name": {
+				Type:        schema.TypeString,
+				Description: "Class name of objects whose faults are retrieved, e.g. fvAEPg.",
+				Optional:    true,
+			},
+			"severity": {
+				Type:         schema.TypeString,
+				Description:  "Minimum severity of faults to be retrieved. Choices: `info`, `warning`, `minor`, `major`, `critical`.",
+				Optional:     true,
+				Default:      "info",
+				ValidateFunc: validation.StringInSlice(FaultSeverities[1:], false),
+			},
+			"code": {
+				Type:        schema.TypeString,
+				Description: "Only retrieve faults with this code, e.g. F0467.",
+				Optional:    true,
+			},
+			"lifecycle_state": {
+				Type:         schema.TypeString,
+				Description:  "Only retrieve faults in this lifecycle state. Choices: `soaking`, `soaking-clearing`, `raised`, `raised-clearing`, `retaining`. By default, faults in all states except `retaining` are retrieved.",
+				Optional:     true,
+				ValidateFunc: validation.StringInSlice([]string{"soaking", "soaking-clearing", "raised", "raised-clearing", "retaining"}, false),
+			},
+			"faults": {
+				Type:        schema.TypeList,
+				Description: "List of faults.",
+				Computed:    true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"dn": {
+							Type:        schema.TypeString,
+							Description: "Distinguished name of the fault instance.",
+							Computed:    true,
+						},
+						"code": {
+							Type:        schema.TypeString,
+							Description: "Fault code.",
+							Computed:    true,
+						},
+						"severity": {
+							Type:        schema.TypeString,
+							Description: "Fault severity.",
+							Computed:    true,
+						},
+						"lifecycle_state": {
+							Type:        schema.TypeString,
+							Description: "Fault lifecycle state.",
+							Computed:    true,
+						},
+						"description": {
+							Type:        schema.TypeString,
+							Description: "Fault description.",
+							Computed:    true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func dataSourceAciFaultsRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	dn := d.Get("dn").(string)
+	className := d.Get("class_name").(string)
+	id := dn
+	path := faultsPath(dn)
+	if dn == "" {
+		id = className
+		path = "/api/class/" + url.PathEscape(className) + ".json?rsp-subtree-include=faults,no-scoped"
+	}
+	ctx = logContext(ctx, meta)
+	tflog.Debug(ctx, "Beginning Read", map[string]interface{}{"id": id})
+
+	faults, diags := queryFaults(ctx, meta, path)
+	if diags.HasError() {
+		return diags
+	}
+
+	code := d.Get("code").(string)
+	lifecycle := d.Get("lifecycle_state").(string)
+	faultList := make([]interface{}, 0)
+	for _, fault := range filterFaults(faults, d.Get("severity").(string), code, lifecycle) {
+		faultList = append(faultList, map[string]interface{}{
+			"dn":              fault.Dn,
+			"code":            fault.Code,
+			"severity":        fault.Severity,
+			"lifecycle_state": fault.Lifecycle,
+			"description":     fault.Descr,
+		})
+	}
+	d.Set("faults", faultList)
+	d.SetId(id)
+
+	tflog.Debug(ctx, "Read finished successfully", map[string]interface{}{"id": d.Id()})
+	return nil
+}
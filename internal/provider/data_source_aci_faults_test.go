@@ -0,0 +1,30 @@
+package provider
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func TestAccDataSourceAciFaults_tenant(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: providerFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccDataSourceAciFaultsConfigTenant,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("data.aci_faults.infra", "id", "uni/tn-infra"),
+					resource.TestCheckResourceAttrSet("data.aci_faults.infra", "faults.#"),
+				),
+			},
+		},
+	})
+}
+
+const testAccDataSourceAciFaultsConfigTenant = `
+data "aci_faults" "infra" {
+  dn       = "uni/tn-infra"
+  severity = "critical"
+}
+`
@@ -0,0 +1,108 @@
+package provider
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func dataSourceAciHealth() *schema.Resource {
+	return &schema.Resource{
+		Description: "This data source can read the health score of an ACI object, e.g. `topology/health` for the overall fabric health.",
+
+		ReadContext: dataSourceAciHealthRead,
+
+		Timeouts: &schema.ResourceTimeout{
+			Read: schema.DefaultTimeout(DefaultTimeout),
+		},
+
+		Schema: map[string]*schema.Schema{
+			"id": {
+				Description: "The distinguished name of the object.",
+				Type:        schema.TypeString,
+				Computed:    true,
+			},
+			"dn": {
+				Type:         schema.TypeString,
+				Description:  "Distinguished name of object whose health score is retrieved, e.g. uni/tn-EXAMPLE_TENANT or topology/health.",
+				Required:     true,
+				ValidateFunc: validateDn,
+			},
+			"current": {
+				Type:        schema.TypeInt,
+				Description: "Current health score between 0 and 100.",
+				Computed:    true,
+			},
+			"previous": {
+				Type:        schema.TypeInt,
+				Description: "Previous health score between 0 and 100.",
+				Computed:    true,
+			},
+			"max_severity": {
+				Type:        schema.TypeString,
+				Description: "Highest severity of faults contributing to the health score.",
+				Computed:    true,
+			},
+		},
+	}
+}
+
+func dataSourceAciHealthRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	dn := d.Get("dn").(string)
+	ctx = logContext(ctx, meta)
+	tflog.Debug(ctx, "Beginning Read", map[string]interface{}{"id": dn})
+
+	path := dnUrlPath(dn) + "?rsp-subtree-include=health"
+	var obj *restObject
+	for attempts := 0; ; attempts++ {
+		cont, diags := apicRestRequest(logAttempt(ctx, attempts), meta, "GET", path, nil)
+		if !diags.HasError() {
+			if cont == nil {
+				return diag.Errorf("Object %s not found", dn)
+			}
+			obj, diags = decodeSingleObject(cont, "")
+			if diags.HasError() {
+				return diags
+			}
+			break
+		}
+		if ok := backoff(ctx, attempts, meta.(apiClient).Retries); !ok {
+			return diags
+		}
+		tflog.SubsystemWarn(ctx, logRetry, "Failed to read health score", map[string]interface{}{"error": diags[0].Summary, "attempt": attempts})
+	}
+
+	health, ok := decodeHealth(*obj)
+	if !ok {
+		return diag.Errorf("Object %s does not have a health score", dn)
+	}
+	current, err := strconv.Atoi(health.Attributes["cur"])
+	if err != nil {
+		return diag.Errorf("Invalid health score of object %s: %s", dn, health.Attributes["cur"])
+	}
+	previous, _ := strconv.Atoi(health.Attributes["prev"])
+	d.Set("current", current)
+	d.Set("previous", previous)
+	d.Set("max_severity", health.Attributes["maxSev"])
+	d.SetId(dn)
+
+	tflog.Debug(ctx, "Read finished successfully", map[string]interface{}{"id": d.Id()})
+	return nil
+}
+
+// decodeHealth returns the health score of an object, which is either the object itself, e.g.
+// 'fabricHealthTotal', or a 'healthInst' child.
+func decodeHealth(obj restObject) (restObject, bool) {
+	if obj.ClassName == "healthInst" || obj.ClassName == "fabricHealthTotal" {
+		return obj, true
+	}
+	for _, child := range obj.Children {
+		if child.ClassName == "healthInst" {
+			return child, true
+		}
+	}
+	return restObject{}, false
+}
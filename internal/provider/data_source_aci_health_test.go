@@ -0,0 +1,29 @@
+package provider
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func TestAccDataSourceAciHealth_fabric(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: providerFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccDataSourceAciHealthConfigFabric,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("data.aci_health.fabric", "id", "topology/health"),
+					resource.TestCheckResourceAttrSet("data.aci_health.fabric", "current"),
+				),
+			},
+		},
+	})
+}
+
+const testAccDataSourceAciHealthConfigFabric = `
+data "aci_health" "fabric" {
+  dn = "topology/health"
+}
+`
@@ -2,9 +2,8 @@ package provider
 
 import (
 	"context"
-	"log"
 
-	"github.com/ciscoecosystem/aci-go-client/container"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 )
@@ -15,6 +14,10 @@ func dataSourceAciRest() *schema.Resource {
 
 		ReadContext: dataSourceAciRestRead,
 
+		Timeouts: &schema.ResourceTimeout{
+			Read: schema.DefaultTimeout(DefaultTimeout),
+		},
+
 		Schema: map[string]*schema.Schema{
 			"id": {
 				Description: "The distinguished name of the object.",
@@ -22,9 +25,10 @@ func dataSourceAciRest() *schema.Resource {
 				Computed:    true,
 			},
 			"dn": {
-				Type:        schema.TypeString,
-				Description: "Distinguished name of object to be retrieved, e.g. uni/tn-EXAMPLE_TENANT.",
-				Required:    true,
+				Type:         schema.TypeString,
+				Description:  "Distinguished name of object to be retrieved, e.g. uni/tn-EXAMPLE_TENANT.",
+				Required:     true,
+				ValidateFunc: validateDn,
 			},
 			"class_name": {
 				Type:        schema.TypeString,
@@ -60,15 +64,16 @@ func dataSourceAciRest() *schema.Resource {
 }
 
 func dataSourceAciRestRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
-	log.Printf("[DEBUG] %s: Beginning Read", d.Id())
+	ctx = logContext(ctx, meta)
+	tflog.Debug(ctx, "Beginning Read", map[string]interface{}{"id": d.Id()})
 
 	for attempts := 0; ; attempts++ {
-		cont, diags := ApicRest(d, meta, "GET", true)
+		cont, diags := ApicRest(ctx, d, meta, "GET", true, attempts)
 		if diags.HasError() {
-			if ok := backoff(attempts, meta.(apiClient).Retries); !ok {
+			if ok := backoff(ctx, attempts, meta.(apiClient).Retries); !ok {
 				return diags
 			}
-			log.Printf("[ERROR] Failed to read object: %s, retries: %v", diags[0].Summary, attempts)
+			tflog.SubsystemWarn(ctx, logRetry, "Failed to read object", map[string]interface{}{"error": diags[0].Summary, "attempt": attempts})
 			continue
 		}
 
@@ -78,69 +83,34 @@ func dataSourceAciRestRead(ctx context.Context, d *schema.ResourceData, meta int
 			break
 		}
 
-		data, err := cont.Search("imdata").Index(0).ChildrenMap()
-		if err != nil {
-			return diag.FromErr(err)
-		}
-
-		var className string
-		var obj *container.Container
-		for k, o := range data {
-			className = k
-			obj = o
+		obj, diags := decodeSingleObject(cont, "")
+		if diags.HasError() {
+			if ok := backoff(ctx, attempts, meta.(apiClient).Retries); !ok {
+				return diags
+			}
+			tflog.SubsystemWarn(ctx, logDecode, "Failed to decode response after reading object", map[string]interface{}{"error": diags[0].Summary, "attempt": attempts})
+			continue
 		}
 		// Set class_name
-		d.Set("class_name", className)
+		d.Set("class_name", obj.ClassName)
 
 		// Set content
-		contentMap := obj.Search("attributes").Data().(map[string]interface{})
-		d.Set("content", contentMap)
-
-		if obj.Exists("children") {
-			children := obj.Search("children")
-			childCount, err := children.ArrayCount()
-			if err != nil {
-				if ok := backoff(attempts, meta.(apiClient).Retries); !ok {
-					return diag.FromErr(err)
-				}
-				log.Printf("[ERROR] Failed to decode response after reading object: %s, retries: %v", diags[0].Summary, attempts)
-				continue
-			}
-
-			childrenSet := make([]interface{}, 0, 1)
-			for i := 0; i < childCount; i++ {
-				childMap := make(map[string]interface{})
-				childData, err := children.Index(i).ChildrenMap()
-				if err != nil {
-					return diag.FromErr(err)
-				}
-
-				var childClassName string
-				var childObj *container.Container
-				for cn, o := range childData {
-					childClassName = cn
-					childObj = o
-				}
-				// Set child class_name
-				childMap["class_name"] = childClassName
-
-				// Set child content
-				childContentMap := childObj.Search("attributes").Data().(map[string]interface{})
-				childMap["content"] = childContentMap
-
-				childrenSet = append(childrenSet, childMap)
-			}
-
-			d.Set("child", childrenSet)
-		} else {
-			d.Set("child", make([]interface{}, 0, 1))
+		d.Set("content", obj.Attributes)
+
+		childrenSet := make([]interface{}, 0, 1)
+		for _, child := range obj.Children {
+			childrenSet = append(childrenSet, map[string]interface{}{
+				"class_name": child.ClassName,
+				"content":    child.Attributes,
+			})
 		}
+		d.Set("child", childrenSet)
 
 		// Set id
 		d.SetId(d.Get("dn").(string))
 		break
 	}
 
-	log.Printf("[DEBUG] %s: Read finished successfully", d.Id())
+	tflog.Debug(ctx, "Read finished successfully", map[string]interface{}{"id": d.Id()})
 	return nil
 }
@@ -0,0 +1,334 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/ciscoecosystem/aci-go-client/client"
+	"github.com/ciscoecosystem/aci-go-client/container"
+)
+
+// Attributes which are never rendered as part of generated content
+var GenerateIgnoreAttr = []string{"status", "childAction"}
+
+var resourceNameRegexp = regexp.MustCompile(`[^A-Za-z0-9_-]+`)
+var hclIdentifierRegexp = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_-]*$`)
+
+type generateObject struct {
+	ClassName string
+	Dn        string
+	Rn        string
+	Content   map[string]string
+	Children  []*generateObject
+}
+
+// Generate implements the "generate" subcommand of the provider binary. It reads an APIC
+// subtree, either from a live APIC or a JSON export, and writes "aci_rest" resources
+// and matching import blocks to the configured output.
+func Generate(args []string, stdout io.Writer) error {
+	fs := flag.NewFlagSet("generate", flag.ContinueOnError)
+	dn := fs.String("dn", "", "Distinguished name of the root object of the subtree, e.g. uni/tn-EXAMPLE_TENANT.")
+	input := fs.String("input", "", "Read the subtree from a JSON export instead of querying the APIC.")
+	output := fs.String("output", "", "Write the generated configuration to this file instead of stdout.")
+	children := fs.Bool("children", false, "Render objects without children of their own as child blocks of their parent, unless other objects of the same class have children.")
+	url := fs.String("url", os.Getenv("ACI_URL"), "URL of the Cisco ACI web interface. Defaults to ACI_URL.")
+	username := fs.String("username", os.Getenv("ACI_USERNAME"), "Username for the APIC Account. Defaults to ACI_USERNAME.")
+	password := fs.String("password", os.Getenv("ACI_PASSWORD"), "Password for the APIC Account. Defaults to ACI_PASSWORD.")
+	loginDomain := fs.String("login-domain", os.Getenv("ACI_LOGIN_DOMAIN"), "Login domain of the APIC Account. Defaults to ACI_LOGIN_DOMAIN.")
+	privateKey := fs.String("private-key", os.Getenv("ACI_PRIVATE_KEY"), "PEM encoded private key or path to a file for signature calculation. Defaults to ACI_PRIVATE_KEY.")
+	privateKeyPassphrase := fs.String("private-key-passphrase", os.Getenv("ACI_PRIVATE_KEY_PASSPHRASE"), "Passphrase to decrypt an encrypted private key. Defaults to ACI_PRIVATE_KEY_PASSPHRASE.")
+	certName := fs.String("cert-name", os.Getenv("ACI_CERT_NAME"), "Certificate name for the User in Cisco ACI. Defaults to ACI_CERT_NAME.")
+	proxyUrl := fs.String("proxy-url", os.Getenv("ACI_PROXY_URL"), "Proxy Server URL with port number. Defaults to ACI_PROXY_URL.")
+	insecure := fs.Bool("insecure", true, "Allow insecure HTTPS client.")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	var cont *container.Container
+	if *input != "" {
+		data, err := ioutil.ReadFile(*input)
+		if err != nil {
+			return err
+		}
+		cont, err = container.ParseJSON(data)
+		if err != nil {
+			return fmt.Errorf("Failed to parse JSON export %s: %s", *input, err)
+		}
+	} else {
+		if *dn == "" {
+			return fmt.Errorf("Either -dn or -input must be provided")
+		}
+		cl := apiClient{
+			Username:             *username,
+			Password:             *password,
+			LoginDomain:          *loginDomain,
+			URL:                  *url,
+			IsInsecure:           *insecure,
+			PrivateKey:           *privateKey,
+			PrivateKeyPassphrase: *privateKeyPassphrase,
+			Certname:             *certName,
+			ProxyUrl:             *proxyUrl,
+		}
+		if diags := cl.Valid(context.Background()); diags.HasError() {
+			return errors.New(diags[0].Summary)
+		}
+		var err error
+		if cl.Signer, err = cl.newSigner(); err != nil {
+			return err
+		}
+		cont, err = generateQuery(cl, *dn)
+		if err != nil {
+			return err
+		}
+	}
+
+	root, err := generateDecode(cont, *dn)
+	if err != nil {
+		return err
+	}
+
+	out := stdout
+	if *output != "" {
+		f, err := os.Create(*output)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		out = f
+	}
+
+	_, err = io.WriteString(out, generateHcl(root, *children))
+	return err
+}
+
+func generateQuery(cl apiClient, dn string) (*container.Container, error) {
+	path := dnUrlPath(dn) + "?rsp-subtree=full&rsp-prop-include=config-only"
+	aciClient := cl.newClient(cl.URL)
+	req, err := cl.newRequest(context.Background(), aciClient, "GET", path, nil)
+	if err != nil {
+		return nil, err
+	}
+	cont, _, err := aciClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if err = client.CheckForErrors(cont, "GET", false); err != nil {
+		return nil, err
+	}
+	return cont, nil
+}
+
+// generateDecode accepts either an APIC response ({"imdata": [...]}) or a single
+// exported object ({"fvTenant": {...}}) and returns the decoded object tree.
+func generateDecode(cont *container.Container, dn string) (*generateObject, error) {
+	var obj restObject
+	if cont.Exists("imdata") {
+		resp, diags := decodeResponse(cont)
+		if diags.HasError() {
+			return nil, fmt.Errorf("%s", diags[0].Summary)
+		}
+		if len(resp.Objects) == 0 {
+			return nil, fmt.Errorf("Object %s not found", dn)
+		}
+		obj = resp.Objects[0]
+	} else {
+		data, ok := cont.Data().(map[string]interface{})
+		if !ok || len(data) != 1 {
+			return nil, fmt.Errorf("Unexpected format of object, expected a single class")
+		}
+		for className, value := range data {
+			o, err := decodeObject(className, value)
+			if err != nil {
+				return nil, err
+			}
+			obj = o
+		}
+	}
+	return generateDecodeObject(obj, "", dn)
+}
+
+func generateDecodeObject(obj restObject, parentDn string, dn string) (*generateObject, error) {
+	o := &generateObject{
+		ClassName: obj.ClassName,
+		Content:   make(map[string]string),
+	}
+	if v := obj.Attributes["dn"]; v != "" {
+		o.Dn = v
+	} else if rn := obj.Attributes["rn"]; rn != "" && parentDn != "" {
+		o.Dn = parentDn + "/" + rn
+	} else {
+		o.Dn = dn
+	}
+	if o.Dn == "" {
+		return nil, fmt.Errorf("Unable to determine dn of object of class %s, use -dn to provide it", obj.ClassName)
+	}
+	if _, err := parseDn(o.Dn); err != nil {
+		return nil, err
+	}
+	o.Rn = dnRn(o.Dn)
+
+	for attr, value := range obj.Attributes {
+		if containsString(IgnoreAttr, attr) || containsString(GenerateIgnoreAttr, attr) {
+			continue
+		}
+		o.Content[attr] = value
+	}
+
+	for _, child := range obj.Children {
+		c, err := generateDecodeObject(child, o.Dn, "")
+		if err != nil {
+			return nil, err
+		}
+		o.Children = append(o.Children, c)
+	}
+	sort.Slice(o.Children, func(i, j int) bool { return o.Children[i].Dn < o.Children[j].Dn })
+	return o, nil
+}
+
+func generateHcl(root *generateObject, children bool) string {
+	var b strings.Builder
+	names := make(map[string]int)
+	var walk func(o *generateObject, parent string)
+	walk = func(o *generateObject, parent string) {
+		name := generateResourceName(o, names)
+		// The import ID lists the classes of child blocks, which imports all children of these classes.
+		// A class is therefore only rendered as blocks if none of its objects has children of its own.
+		var nestedClasses []string
+		for _, c := range o.Children {
+			if len(c.Children) > 0 && !containsString(nestedClasses, c.ClassName) {
+				nestedClasses = append(nestedClasses, c.ClassName)
+			}
+		}
+		var nested []*generateObject
+		var blocks []*generateObject
+		var blockClasses []string
+		for _, c := range o.Children {
+			if children && !containsString(nestedClasses, c.ClassName) {
+				blocks = append(blocks, c)
+				if !containsString(blockClasses, c.ClassName) {
+					blockClasses = append(blockClasses, c.ClassName)
+				}
+			} else {
+				nested = append(nested, c)
+			}
+		}
+
+		if b.Len() > 0 {
+			b.WriteString("\n")
+		}
+		fmt.Fprintf(&b, "resource \"aci_rest\" %q {\n", name)
+		writeHclAttributes(&b, "  ", [][2]string{{"dn", hclString(o.Dn)}, {"class_name", hclString(o.ClassName)}})
+		writeHclMap(&b, "  ", "content", o.Content, false)
+		for _, c := range blocks {
+			b.WriteString("\n  child {\n")
+			writeHclAttributes(&b, "    ", [][2]string{{"rn", hclString(c.Rn)}, {"class_name", hclString(c.ClassName)}})
+			// Imported children track all of their attributes, therefore empty values are kept
+			writeHclMap(&b, "    ", "content", c.Content, true)
+			b.WriteString("  }\n")
+		}
+		if parent != "" {
+			fmt.Fprintf(&b, "\n  depends_on = [aci_rest.%s]\n", parent)
+		}
+		b.WriteString("}\n")
+
+		b.WriteString("\nimport {\n")
+		// The import ID lists the rendered content keys, so that the imported state matches the configuration
+		sort.Strings(blockClasses)
+		id := importId(o.ClassName, o.Dn, blockClasses, generateContentKeys(o.Content))
+		writeHclAttributes(&b, "  ", [][2]string{{"to", "aci_rest." + name}, {"id", hclString(id)}})
+		b.WriteString("}\n")
+
+		for _, c := range nested {
+			walk(c, name)
+		}
+	}
+	walk(root, "")
+	return b.String()
+}
+
+func generateResourceName(o *generateObject, names map[string]int) string {
+	rn := o.Rn
+	if i := strings.Index(rn, "-"); i >= 0 {
+		rn = rn[i+1:]
+	}
+	name := o.ClassName
+	if rn = strings.Trim(resourceNameRegexp.ReplaceAllString(rn, "_"), "_"); rn != "" {
+		name += "_" + rn
+	}
+	names[name]++
+	if names[name] > 1 {
+		name += "_" + strconv.Itoa(names[name])
+	}
+	return name
+}
+
+func generateContentKeys(content map[string]string) []string {
+	keys := make([]string, 0, len(content))
+	for k, v := range content {
+		if v != "" {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func writeHclAttributes(b *strings.Builder, indent string, attrs [][2]string) {
+	width := 0
+	for _, a := range attrs {
+		if len(a[0]) > width {
+			width = len(a[0])
+		}
+	}
+	for _, a := range attrs {
+		fmt.Fprintf(b, "%s%-*s = %s\n", indent, width, a[0], a[1])
+	}
+}
+
+func writeHclMap(b *strings.Builder, indent string, name string, m map[string]string, keepEmpty bool) {
+	keys := generateContentKeys(m)
+	if keepEmpty {
+		keys = keys[:0]
+		for k := range m {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+	}
+	if len(keys) == 0 {
+		return
+	}
+	attrs := make([][2]string, 0, len(keys))
+	for _, k := range keys {
+		key := k
+		if !hclIdentifierRegexp.MatchString(k) {
+			key = hclString(k)
+		}
+		attrs = append(attrs, [2]string{key, hclString(m[k])})
+	}
+	fmt.Fprintf(b, "%s%s = {\n", indent, name)
+	writeHclAttributes(b, indent+"  ", attrs)
+	fmt.Fprintf(b, "%s}\n", indent)
+}
+
+// hclString renders a quoted HCL string literal, escaping template sequences.
+func hclString(s string) string {
+	r := strings.NewReplacer(
+		`\`, `\\`,
+		`"`, `\"`,
+		"\n", `\n`,
+		"\r", `\r`,
+		"\t", `\t`,
+		"${", "$${",
+		"%{", "%%{",
+	)
+	return `"` + r.Replace(s) + `"`
+}
@@ -0,0 +1,146 @@
+package provider
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const testGenerateExport = `{
+  "totalCount": "1",
+  "imdata": [
+    {
+      "fvTenant": {
+        "attributes": {
+          "dn": "uni/tn-EXAMPLE",
+          "name": "EXAMPLE",
+          "descr": "Say \"hi\" to ${var}",
+          "annotation": "orchestrator:terraform",
+          "nameAlias": ""
+        },
+        "children": [
+          {
+            "fvCtx": {
+              "attributes": {
+                "rn": "ctx-VRF1",
+                "name": "VRF1",
+                "descr": ""
+              }
+            }
+          },
+          {
+            "fvAp": {
+              "attributes": {
+                "rn": "ap-AP1",
+                "name": "AP1"
+              },
+              "children": [
+                {
+                  "fvAEPg": {
+                    "attributes": {
+                      "rn": "epg-EPG1",
+                      "name": "EPG1"
+                    }
+                  }
+                }
+              ]
+            }
+          },
+          {
+            "fvAp": {
+              "attributes": {
+                "rn": "ap-AP2",
+                "name": "AP2"
+              }
+            }
+          }
+        ]
+      }
+    }
+  ]
+}`
+
+const testGenerateExpected = `resource "aci_rest" "fvTenant_EXAMPLE" {
+  dn         = "uni/tn-EXAMPLE"
+  class_name = "fvTenant"
+  content = {
+    descr = "Say \"hi\" to $${var}"
+    name  = "EXAMPLE"
+  }
+
+  child {
+    rn         = "ctx-VRF1"
+    class_name = "fvCtx"
+    content = {
+      descr = ""
+      name  = "VRF1"
+    }
+  }
+}
+
+import {
+  to = aci_rest.fvTenant_EXAMPLE
+  id = "fvTenant:uni/tn-EXAMPLE:fvCtx:descr,name"
+}
+
+resource "aci_rest" "fvAp_AP1" {
+  dn         = "uni/tn-EXAMPLE/ap-AP1"
+  class_name = "fvAp"
+  content = {
+    name = "AP1"
+  }
+
+  child {
+    rn         = "epg-EPG1"
+    class_name = "fvAEPg"
+    content = {
+      name = "EPG1"
+    }
+  }
+
+  depends_on = [aci_rest.fvTenant_EXAMPLE]
+}
+
+import {
+  to = aci_rest.fvAp_AP1
+  id = "fvAp:uni/tn-EXAMPLE/ap-AP1:fvAEPg:name"
+}
+
+resource "aci_rest" "fvAp_AP2" {
+  dn         = "uni/tn-EXAMPLE/ap-AP2"
+  class_name = "fvAp"
+  content = {
+    name = "AP2"
+  }
+
+  depends_on = [aci_rest.fvTenant_EXAMPLE]
+}
+
+import {
+  to = aci_rest.fvAp_AP2
+  id = "fvAp:uni/tn-EXAMPLE/ap-AP2::name"
+}
+`
+
+func TestGenerate(t *testing.T) {
+	dir, err := ioutil.TempDir("", "generate")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	input := filepath.Join(dir, "export.json")
+	if err := ioutil.WriteFile(input, []byte(testGenerateExport), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	if err := Generate([]string{"-input", input, "-children"}, &out); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if out.String() != testGenerateExpected {
+		t.Fatalf("unexpected output:\n%s", out.String())
+	}
+}
@@ -2,14 +2,19 @@ package provider
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"net/http"
 	"os"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/ciscoecosystem/aci-go-client/client"
+	"github.com/ciscoecosystem/aci-go-client/container"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
 )
 
 func init() {
@@ -34,9 +39,9 @@ func New(version string) func() *schema.Provider {
 			Schema: map[string]*schema.Schema{
 				"username": {
 					Type:        schema.TypeString,
-					Required:    true,
+					Optional:    true,
 					DefaultFunc: schema.EnvDefaultFunc("ACI_USERNAME", nil),
-					Description: "Username for the APIC Account. This can also be set as the ACI_USERNAME environment variable.",
+					Description: "Username for the APIC Account. This can also be set as the ACI_USERNAME environment variable. Must be provided unless returned by the `credential_command`.",
 				},
 				"password": {
 					Type:        schema.TypeString,
@@ -44,11 +49,35 @@ func New(version string) func() *schema.Provider {
 					DefaultFunc: schema.EnvDefaultFunc("ACI_PASSWORD", nil),
 					Description: "Password for the APIC Account. This can also be set as the ACI_PASSWORD environment variable.",
 				},
+				"password_file": {
+					Type:        schema.TypeString,
+					Optional:    true,
+					DefaultFunc: schema.EnvDefaultFunc("ACI_PASSWORD_FILE", nil),
+					Description: "Path to a file containing the password for the APIC Account, which takes precedence over `password`. This can also be set as the ACI_PASSWORD_FILE environment variable.",
+				},
+				"credential_command": {
+					Type:        schema.TypeList,
+					Optional:    true,
+					Elem:        &schema.Schema{Type: schema.TypeString},
+					Description: "Command and arguments of an executable which writes the credentials as a JSON object with `username` and either `password` or `private_key`, `private_key_passphrase` and `cert_name` to stdout. The credentials returned take precedence over any other configured credentials. This can also be set as the ACI_CREDENTIAL_COMMAND environment variable, a space-separated list.",
+				},
+				"login_domain": {
+					Type:        schema.TypeString,
+					Optional:    true,
+					DefaultFunc: schema.EnvDefaultFunc("ACI_LOGIN_DOMAIN", nil),
+					Description: "Login domain of the APIC Account, e.g. a RADIUS, TACACS+ or LDAP domain. Only supported with password authentication. This can also be set as the ACI_LOGIN_DOMAIN environment variable.",
+				},
 				"url": {
 					Type:        schema.TypeString,
-					Required:    true,
+					Optional:    true,
 					DefaultFunc: schema.EnvDefaultFunc("ACI_URL", nil),
-					Description: "URL of the Cisco ACI web interface. This can also be set as the ACI_URL environment variable.",
+					Description: "URL of the Cisco ACI web interface. This can also be set as the ACI_URL environment variable. Either `url` or `urls` must be provided.",
+				},
+				"urls": {
+					Type:        schema.TypeList,
+					Optional:    true,
+					Elem:        &schema.Schema{Type: schema.TypeString},
+					Description: "URLs of the controllers of an APIC cluster. The first reachable controller is used and requests fail over to the next controller if a connection is refused or times out. This can also be set as the ACI_URLS environment variable, a comma-separated list of URLs.",
 				},
 				"insecure": {
 					Type:     schema.TypeBool,
@@ -65,7 +94,14 @@ func New(version string) func() *schema.Provider {
 					Type:        schema.TypeString,
 					Optional:    true,
 					DefaultFunc: schema.EnvDefaultFunc("ACI_PRIVATE_KEY", nil),
-					Description: "Private key path for signature calculation. This can also be set as the ACI_PRIVATE_KEY environment variable.",
+					Description: "PEM encoded private key or path to a file for signature calculation. RSA and EC keys are supported, as well as encrypted PKCS#8 keys. This can also be set as the ACI_PRIVATE_KEY environment variable.",
+				},
+				"private_key_passphrase": {
+					Type:        schema.TypeString,
+					Optional:    true,
+					Sensitive:   true,
+					DefaultFunc: schema.EnvDefaultFunc("ACI_PRIVATE_KEY_PASSPHRASE", nil),
+					Description: "Passphrase to decrypt an encrypted PKCS#8 private key, which uses PBES2 with PBKDF2 (HMAC-SHA1 or HMAC-SHA256) or scrypt and AES-CBC or 3DES, e.g. as created by `openssl pkcs8 -topk8 -v2 aes-256-cbc`. This can also be set as the ACI_PRIVATE_KEY_PASSPHRASE environment variable.",
 				},
 				"cert_name": {
 					Type:        schema.TypeString,
@@ -79,6 +115,33 @@ func New(version string) func() *schema.Provider {
 					DefaultFunc: schema.EnvDefaultFunc("ACI_PROXY_URL", nil),
 					Description: "Proxy Server URL with port number. This can also be set as the ACI_PROXY_URL environment variable.",
 				},
+				"ca_certificate": {
+					Type:        schema.TypeString,
+					Optional:    true,
+					DefaultFunc: schema.EnvDefaultFunc("ACI_CA_CERTIFICATE", nil),
+					Description: "PEM encoded CA certificate or path to a file to verify the APIC certificate. If provided, the APIC certificate is always verified. This can also be set as the ACI_CA_CERTIFICATE environment variable.",
+				},
+				"tls_server_name": {
+					Type:        schema.TypeString,
+					Optional:    true,
+					DefaultFunc: schema.EnvDefaultFunc("ACI_TLS_SERVER_NAME", nil),
+					Description: "Server name to verify the APIC certificate against, e.g. if the APIC is accessed by its IP address. If provided, the APIC certificate is always verified. This can also be set as the ACI_TLS_SERVER_NAME environment variable.",
+				},
+				"client_certificate": {
+					Type:         schema.TypeString,
+					Optional:     true,
+					DefaultFunc:  schema.EnvDefaultFunc("ACI_CLIENT_CERTIFICATE", nil),
+					RequiredWith: []string{"client_key"},
+					Description:  "PEM encoded client certificate or path to a file for mutual TLS authentication, e.g. with a proxy. This can also be set as the ACI_CLIENT_CERTIFICATE environment variable.",
+				},
+				"client_key": {
+					Type:         schema.TypeString,
+					Optional:     true,
+					Sensitive:    true,
+					DefaultFunc:  schema.EnvDefaultFunc("ACI_CLIENT_KEY", nil),
+					RequiredWith: []string{"client_certificate"},
+					Description:  "PEM encoded private key of the client certificate or path to a file. This can also be set as the ACI_CLIENT_KEY environment variable.",
+				},
 				"retries": {
 					Type:     schema.TypeInt,
 					Optional: true,
@@ -119,9 +182,59 @@ func New(version string) func() *schema.Provider {
 					},
 					Description: "Only mock API calls. This is mainly for troubleshooting/debugging purposes. This can also be set as the ACI_MOCK environment variable. Defaults to `false`.",
 				},
+				"audit_log_path": {
+					Type:        schema.TypeString,
+					Optional:    true,
+					DefaultFunc: schema.EnvDefaultFunc("ACI_AUDIT_LOG_PATH", nil),
+					Description: "Path to a file to which every request creating, updating or deleting an object is appended as a JSON line, including the payload, the response status and the number of retries. If a request can not be written, the apply fails without sending the request again. Sensitive values are written as SHA-256 hashes. This can also be set as the ACI_AUDIT_LOG_PATH environment variable.",
+				},
+				"dry_run_path": {
+					Type:        schema.TypeString,
+					Optional:    true,
+					DefaultFunc: schema.EnvDefaultFunc("ACI_DRY_RUN_PATH", nil),
+					Description: "Path to a file to which requests creating, updating or deleting objects are written as JSON lines instead of sending them to the APIC. Objects are still read from the APIC. Created and updated objects are reported as warnings, so that the requests of dependent objects are written as well. Updated objects keep their prior state and created objects are added to state until the next refresh. The apply fails after writing a delete request to keep the object in state, as deleted objects are always removed from state. Sensitive values are written as salted hashes. This can also be set as the ACI_DRY_RUN_PATH environment variable.",
+				},
+				"read_only": {
+					Type:     schema.TypeBool,
+					Optional: true,
+					DefaultFunc: func() (interface{}, error) {
+						if v := os.Getenv("ACI_READ_ONLY"); v != "" {
+							return strconv.ParseBool(v)
+						}
+						return false, nil
+					},
+					Description: "Refuse to create, update or delete objects, e.g. to check for drift with read-only credentials. Planned changes fail during plan, except for deletions which fail during apply. This can also be set as the ACI_READ_ONLY environment variable. Defaults to `false`.",
+				},
+				"normalization": {
+					Type:        schema.TypeList,
+					Optional:    true,
+					Description: "Custom rules to normalize attribute values before comparing them with the values returned by the APIC. By default, common attributes with MAC addresses, IP addresses, boolean values and comma-separated flags are normalized, e.g. `mac`, `ip`, `arpFlood` and `ctrl`.",
+					Elem: &schema.Resource{
+						Schema: map[string]*schema.Schema{
+							"class_name": {
+								Type:        schema.TypeString,
+								Optional:    true,
+								Description: "Class name the rule applies to. If omitted, the rule applies to all classes.",
+							},
+							"attribute": {
+								Type:        schema.TypeString,
+								Required:    true,
+								Description: "Attribute name the rule applies to.",
+							},
+							"rule": {
+								Type:         schema.TypeString,
+								Required:     true,
+								ValidateFunc: validation.StringInSlice([]string{"none", "bool", "ip", "mac", "flags", "case_insensitive"}, false),
+								Description:  "Normalization rule. Choices: `none`, `bool`, `ip`, `mac`, `flags`, `case_insensitive`. `none` disables the built-in normalization.",
+							},
+						},
+					},
+				},
 			},
 			DataSourcesMap: map[string]*schema.Resource{
-				"aci_rest": dataSourceAciRest(),
+				"aci_rest":   dataSourceAciRest(),
+				"aci_faults": dataSourceAciFaults(),
+				"aci_health": dataSourceAciHealth(),
 			},
 			ResourcesMap: map[string]*schema.Resource{
 				"aci_rest": resourceAciRest(),
@@ -135,20 +248,35 @@ func New(version string) func() *schema.Provider {
 }
 
 type apiClient struct {
-	Username     string
-	Password     string
-	URL          string
-	IsInsecure   bool
-	PrivateKey   string
-	Certname     string
-	ProxyUrl     string
-	Retries      int
-	IsAnnotation bool
-	IsMock       bool
-	Client       *client.Client
+	Username             string
+	Password             string
+	LoginDomain          string
+	URL                  string
+	URLs                 []string
+	IsInsecure           bool
+	PrivateKey           string
+	PrivateKeyPassphrase string
+	Certname             string
+	ProxyUrl             string
+	CaCertificate        string
+	TlsServerName        string
+	ClientCertificate    string
+	ClientKey            string
+	HttpClient           *http.Client
+	Retries              int
+	IsAnnotation         bool
+	IsMock               bool
+	IsReadOnly           bool
+	DryRunPath           string
+	AuditLogPath         string
+	NormalizationRules   []normalizationRule
+	Signer               *requestSigner
+	Controllers          *controllers
 }
 
-func (c apiClient) Valid() diag.Diagnostics {
+// Valid checks the configuration of the client. If the client is connected to the controllers,
+// the private key and certificate name are verified by signing a request to the APIC.
+func (c apiClient) Valid(ctx context.Context) diag.Diagnostics {
 
 	if c.Username == "" {
 		return diag.FromErr(fmt.Errorf("Username must be provided for the ACI provider"))
@@ -163,41 +291,235 @@ func (c apiClient) Valid() diag.Diagnostics {
 		}
 	}
 
-	if c.URL == "" {
+	if c.LoginDomain != "" {
+		if c.Password == "" {
+			return diag.FromErr(fmt.Errorf("login_domain is only supported with password authentication"))
+		}
+		if strings.HasPrefix(c.Username, "apic#") {
+			return diag.FromErr(fmt.Errorf("The username must not include a login domain if login_domain is provided"))
+		}
+	}
+
+	if len(c.controllerUrls()) == 0 {
 		return diag.FromErr(fmt.Errorf("The URL must be provided for the ACI provider"))
 	}
 
+	signer, err := c.newSigner()
+	if err != nil {
+		return diag.FromErr(fmt.Errorf("Invalid private_key: %s", err))
+	}
+
+	// A key which does not match the certificate is only detected by the APIC
+	if signer != nil && c.Controllers != nil && !c.IsMock {
+		c.Signer = signer
+		if _, diags := apicRestRequest(ctx, c, "GET", dnUrlPath(signer.certDn), nil); diags.HasError() {
+			return diag.Errorf("Failed to verify private_key and cert_name for user %s: %s", c.Username, diags[0].Summary)
+		}
+	}
+
 	return nil
 }
 
-func (c apiClient) getClient() interface{} {
+// controllerUrls returns the URLs of all configured controllers.
+func (c apiClient) controllerUrls() []string {
+	urls := make([]string, 0)
+	for _, url := range append([]string{c.URL}, c.URLs...) {
+		if url != "" && !containsString(urls, url) {
+			urls = append(urls, url)
+		}
+	}
+	return urls
+}
+
+func (c apiClient) newClient(url string) *client.Client {
+	// Payloads are logged by the provider with secrets masked. Logins are made by the provider,
+	// as the client enables logging of payloads while logging in, see authenticate.
+	options := []client.Option{client.Insecure(c.IsInsecure), client.ProxyUrl(c.ProxyUrl), client.SkipLoggingPayload(true)}
+	if c.HttpClient != nil {
+		options = append(options, client.HttpClient(c.HttpClient))
+	}
+	// Requests authenticated with a private key are signed by the provider, see newRequest
 	if c.Password != "" {
-		return client.GetClient(c.URL, c.Username, client.Password(c.Password), client.Insecure(c.IsInsecure), client.ProxyUrl(c.ProxyUrl))
-	} else {
-		return client.GetClient(c.URL, c.Username, client.PrivateKey(c.PrivateKey), client.AdminCert(c.Certname), client.Insecure(c.IsInsecure), client.ProxyUrl(c.ProxyUrl))
+		options = append(options, client.Password(c.Password))
+	}
+	return client.NewClient(url, c.loginName(), options...)
+}
+
+// newRequest returns an authenticated request, either signed with the private key or using the
+// session of the client.
+func (c apiClient) newRequest(ctx context.Context, aciClient *client.Client, method string, path string, cont *container.Container) (*http.Request, error) {
+	req, err := aciClient.MakeRestRequest(method, path, cont, false)
+	if err != nil {
+		return nil, err
+	}
+	if c.Signer != nil {
+		if err := c.Signer.signRequest(req, path); err != nil {
+			return nil, fmt.Errorf("Failed to sign request: %s", err)
+		}
+		return req, nil
+	}
+	// The session cookie is added by the provider, so that the client never logs in on its own
+	token, err := c.authenticate(ctx, aciClient)
+	if err != nil {
+		return nil, err
+	}
+	req.AddCookie(&http.Cookie{Name: "APIC-Cookie", Value: token})
+	return req, nil
+}
+
+// authLock serializes logins of concurrent requests, so that only one session is established.
+// Waiting for the lock is canceled with the context of the request.
+var authLock = make(chan struct{}, 1)
+
+// authenticate logs in if the client has no valid session and returns the session token. The
+// login is made by the provider, as the client logs the response including the token.
+func (c apiClient) authenticate(ctx context.Context, aciClient *client.Client) (string, error) {
+	select {
+	case authLock <- struct{}{}:
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+	defer func() { <-authLock }()
+	if aciClient.AuthToken != nil && aciClient.AuthToken.IsValid() {
+		return aciClient.AuthToken.Token, nil
+	}
+
+	body, err := json.Marshal(map[string]interface{}{
+		"aaaUser": map[string]interface{}{
+			"attributes": map[string]string{"name": c.loginName(), "pwd": c.Password},
+		},
+	})
+	if err != nil {
+		return "", err
+	}
+	req, err := aciClient.MakeRestRequestRaw("POST", "/api/aaaLogin.json", body, false)
+	if err != nil {
+		return "", err
+	}
+	cont, _, err := aciClient.Do(req.WithContext(ctx))
+	if err != nil {
+		return "", err
+	}
+	if cont == nil {
+		return "", fmt.Errorf("Empty response")
+	}
+	if err := client.CheckForErrors(cont, "POST", true); err != nil {
+		return "", err
+	}
+
+	attributes := cont.S("imdata").Index(0).S("aaaLogin", "attributes")
+	token, _ := attributes.S("token").Data().(string)
+	if token == "" {
+		return "", fmt.Errorf("Invalid Username or Password")
+	}
+	refreshTimeout, _ := attributes.S("refreshTimeoutSeconds").Data().(string)
+	seconds, err := strconv.Atoi(refreshTimeout)
+	if err != nil {
+		return "", fmt.Errorf("Invalid refresh timeout of session: %s", refreshTimeout)
+	}
+	aciClient.AuthToken = &client.Auth{Token: token, Expiry: time.Now().Add(time.Duration(seconds) * time.Second)}
+	return token, nil
+}
+
+// loginName returns the username including the login domain in the format expected by the APIC.
+func (c apiClient) loginName() string {
+	if c.LoginDomain == "" {
+		return c.Username
 	}
+	return "apic#" + c.LoginDomain + "\\" + c.Username
+}
+
+// Client returns the client of the active controller.
+func (c apiClient) Client() *client.Client {
+	_, cl := c.Controllers.current()
+	return cl
 }
 
 func configure(version string, p *schema.Provider) func(context.Context, *schema.ResourceData) (interface{}, diag.Diagnostics) {
 	return func(c context.Context, d *schema.ResourceData) (interface{}, diag.Diagnostics) {
 		cl := apiClient{
-			Username:     d.Get("username").(string),
-			Password:     d.Get("password").(string),
-			URL:          d.Get("url").(string),
-			IsInsecure:   d.Get("insecure").(bool),
-			PrivateKey:   d.Get("private_key").(string),
-			Certname:     d.Get("cert_name").(string),
-			ProxyUrl:     d.Get("proxy_url").(string),
-			Retries:      d.Get("retries").(int),
-			IsAnnotation: d.Get("annotation").(bool),
-			IsMock:       d.Get("mock").(bool),
-		}
-
-		if diag := cl.Valid(); diag != nil {
+			Username:             d.Get("username").(string),
+			Password:             d.Get("password").(string),
+			LoginDomain:          d.Get("login_domain").(string),
+			URL:                  d.Get("url").(string),
+			URLs:                 make([]string, 0),
+			IsInsecure:           d.Get("insecure").(bool),
+			PrivateKey:           d.Get("private_key").(string),
+			PrivateKeyPassphrase: d.Get("private_key_passphrase").(string),
+			Certname:             d.Get("cert_name").(string),
+			ProxyUrl:             d.Get("proxy_url").(string),
+			CaCertificate:        d.Get("ca_certificate").(string),
+			TlsServerName:        d.Get("tls_server_name").(string),
+			ClientCertificate:    d.Get("client_certificate").(string),
+			ClientKey:            d.Get("client_key").(string),
+			Retries:              d.Get("retries").(int),
+			IsAnnotation:         d.Get("annotation").(bool),
+			IsMock:               d.Get("mock").(bool),
+			IsReadOnly:           d.Get("read_only").(bool),
+			DryRunPath:           d.Get("dry_run_path").(string),
+			AuditLogPath:         d.Get("audit_log_path").(string),
+		}
+
+		for _, url := range d.Get("urls").([]interface{}) {
+			if v, ok := url.(string); ok {
+				cl.URLs = append(cl.URLs, v)
+			}
+		}
+		if v := os.Getenv("ACI_URLS"); v != "" && len(cl.URLs) == 0 {
+			for _, url := range strings.Split(v, ",") {
+				cl.URLs = append(cl.URLs, strings.TrimSpace(url))
+			}
+		}
+
+		command := make([]string, 0)
+		for _, arg := range d.Get("credential_command").([]interface{}) {
+			if v, ok := arg.(string); ok {
+				command = append(command, v)
+			}
+		}
+		if v := os.Getenv("ACI_CREDENTIAL_COMMAND"); v != "" && len(command) == 0 {
+			command = strings.Fields(v)
+		}
+		if err := cl.applyCredentials(c, d.Get("password_file").(string), command); err != nil {
+			return nil, diag.FromErr(err)
+		}
+
+		for _, rule := range d.Get("normalization").([]interface{}) {
+			ruleMap, _ := rule.(map[string]interface{})
+			className, _ := ruleMap["class_name"].(string)
+			attribute, _ := ruleMap["attribute"].(string)
+			ruleName, _ := ruleMap["rule"].(string)
+			cl.NormalizationRules = append(cl.NormalizationRules, normalizationRule{ClassName: className, Attribute: attribute, Rule: ruleName})
+		}
+
+		if cl.AuditLogPath != "" {
+			if err := checkAuditLog(cl.AuditLogPath); err != nil {
+				return nil, diag.FromErr(err)
+			}
+		}
+
+		if cl.tlsConfigured() {
+			httpClient, err := cl.newHttpClient()
+			if err != nil {
+				return nil, diag.FromErr(err)
+			}
+			cl.HttpClient = httpClient
+		}
+
+		c = logContext(c, cl)
+		cl.Controllers = newControllers(cl)
+		if diag := cl.Valid(c); diag != nil {
 			return nil, diag
 		}
 
-		cl.Client = cl.getClient().(*client.Client)
+		// The private key has been verified
+		cl.Signer, _ = cl.newSigner()
+
+		if cl.Controllers.count() > 1 && !cl.IsMock {
+			if err := cl.Controllers.healthCheck(c); err != nil {
+				return nil, diag.FromErr(err)
+			}
+		}
 
 		return cl, nil
 	}
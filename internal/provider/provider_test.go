@@ -1,8 +1,13 @@
 package provider
 
 import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 )
@@ -46,3 +51,51 @@ func testAccPreCheck(t *testing.T) {
 		t.Fatal("ACI_URL env variable must be set for acceptance tests")
 	}
 }
+
+func TestApiClientLoginDomain(t *testing.T) {
+	cl := apiClient{Username: "admin", Password: "password", URL: "https://10.1.1.1", LoginDomain: "TACACS"}
+	if diags := cl.Valid(context.Background()); diags.HasError() {
+		t.Fatalf("unexpected error: %s", diags[0].Summary)
+	}
+	if name := cl.loginName(); name != `apic#TACACS\admin` {
+		t.Fatalf("unexpected login name: %s", name)
+	}
+
+	cl.Username = `apic#TACACS\admin`
+	if diags := cl.Valid(context.Background()); !diags.HasError() {
+		t.Fatalf("expected error for username with login domain")
+	}
+
+	cl = apiClient{Username: "admin", PrivateKey: "admin.key", Certname: "admin.crt", URL: "https://10.1.1.1", LoginDomain: "TACACS"}
+	if diags := cl.Valid(context.Background()); !diags.HasError() {
+		t.Fatalf("expected error for login domain with certificate authentication")
+	}
+}
+
+func TestAuthenticateCanceled(t *testing.T) {
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer srv.Close()
+	defer close(release)
+
+	cl := apiClient{URL: srv.URL, Username: "admin", Password: "password"}
+	aciClient := cl.newClient(cl.URL)
+
+	// The login is canceled with the context
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	if _, err := cl.authenticate(ctx, aciClient); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected login to be canceled, got %v", err)
+	}
+
+	// Waiting for the login of another request is canceled with the context
+	authLock <- struct{}{}
+	defer func() { <-authLock }()
+	ctx, cancel = context.WithCancel(context.Background())
+	cancel()
+	if _, err := cl.authenticate(ctx, aciClient); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected waiting for login to be canceled, got %v", err)
+	}
+}
@@ -3,12 +3,15 @@ package provider
 import (
 	"context"
 	"fmt"
-	"log"
+	"regexp"
 	"strings"
 
 	"github.com/ciscoecosystem/aci-go-client/container"
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
 )
 
 func resourceAciRest() *schema.Resource {
@@ -22,6 +25,14 @@ func resourceAciRest() *schema.Resource {
 		Importer: &schema.ResourceImporter{
 			StateContext: resourceAciRestImport,
 		},
+		CustomizeDiff: resourceAciRestCustomizeDiff,
+
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(DefaultTimeout),
+			Read:   schema.DefaultTimeout(DefaultTimeout),
+			Update: schema.DefaultTimeout(DefaultTimeout),
+			Delete: schema.DefaultTimeout(DefaultTimeout),
+		},
 
 		Schema: map[string]*schema.Schema{
 			"id": {
@@ -30,10 +41,11 @@ func resourceAciRest() *schema.Resource {
 				Computed:    true,
 			},
 			"dn": {
-				Type:        schema.TypeString,
-				Description: "Distinguished name of object being managed including its relative name, e.g. uni/tn-EXAMPLE_TENANT.",
-				Required:    true,
-				ForceNew:    true,
+				Type:         schema.TypeString,
+				Description:  "Distinguished name of object being managed including its relative name, e.g. uni/tn-EXAMPLE_TENANT.",
+				Required:     true,
+				ForceNew:     true,
+				ValidateFunc: validateDn,
 			},
 			"class_name": {
 				Type:        schema.TypeString,
@@ -42,19 +54,56 @@ func resourceAciRest() *schema.Resource {
 				ForceNew:    true,
 			},
 			"content": {
-				Type:        schema.TypeMap,
-				Description: "Map of key-value pairs those needed to be passed to the Model object as parameters. Make sure the key name matches the name with the object parameter in ACI.",
+				Type:             schema.TypeMap,
+				Description:      "Map of key-value pairs those needed to be passed to the Model object as parameters. Make sure the key name matches the name with the object parameter in ACI. Only the attributes configured here are tracked in state. Attributes which are not configured, e.g. in state of earlier versions, do not cause a diff and are removed from state with the next update. Configured attributes which are not in state, e.g. after importing without a list of attributes, are compared to `attributes`. Values which are equivalent to the values returned by the APIC, eg. `true` and `yes`, do not cause a diff.",
+				Optional:         true,
+				Computed:         true,
+				DiffSuppressFunc: suppressNormalizedDiff,
+			},
+			"sensitive_content": {
+				Type:             schema.TypeMap,
+				Description:      "Map of key-value pairs of secret attributes, e.g. passwords, which are never returned by the APIC. Only a salted HMAC-SHA256 hash of each value is stored in state to detect changes.",
+				Optional:         true,
+				Sensitive:        true,
+				Elem:             &schema.Schema{Type: schema.TypeString},
+				DiffSuppressFunc: suppressSensitiveDiff,
+			},
+			"sensitive_salt": {
+				Type:        schema.TypeString,
+				Description: "Random salt of the hashes of sensitive values, which is generated for each resource.",
+				Computed:    true,
+				Sensitive:   true,
+			},
+			"sensitive_content_wo": {
+				Type:         schema.TypeString,
+				Description:  "JSON document with key-value pairs of secret attributes, e.g. `jsonencode({pwd = var.password})`, which is never stored in state or plan. Requires Terraform 1.11 or later. Use `sensitive_content_wo_version` to trigger an update when the values change.",
+				Optional:     true,
+				WriteOnly:    true,
+				Sensitive:    true,
+				ValidateFunc: validateSensitiveContentWo,
+			},
+			"sensitive_content_wo_version": {
+				Type:        schema.TypeString,
+				Description: "Arbitrary value which triggers sending `sensitive_content_wo` to the APIC when changed.",
 				Optional:    true,
+			},
+			"attributes": {
+				Type:        schema.TypeMap,
+				Description: "Map of the configurable attributes of the object as returned by the APIC, including values which are not configured in `content`. Operational values, e.g. `pcTag`, can be read with the `aci_rest` data source.",
 				Computed:    true,
-				DiffSuppressFunc: func(k, old, new string, d *schema.ResourceData) bool {
-					content := d.Get("content")
-					contentStrMap := toStrMap(content.(map[string]interface{}))
-					key := strings.Split(k, ".")[1]
-					if _, ok := contentStrMap[key]; ok {
-						return false
-					}
-					return true
-				},
+			},
+			"payload": {
+				Type:        schema.TypeString,
+				Description: "JSON document sent to the APIC when creating or updating the object, including annotations and children. Sensitive values are replaced by their salted hash. Only updated when the object is changed.",
+				Computed:    true,
+			},
+			"children": {
+				Type:             schema.TypeMap,
+				Description:      "Map of children keyed by their relative name. Each value is a JSON document with the class name and attributes of the child, e.g. `jsonencode({class_name = \"fvCtx\", content = {name = \"VRF1\"}})`. Documents with equivalent attributes, e.g. in a different order or with `true` instead of `yes`, do not cause a diff. The plan shows a change of a child as a change of its whole document, which Terraform renders as a diff of the decoded attributes.",
+				Optional:         true,
+				Elem:             &schema.Schema{Type: schema.TypeString},
+				ValidateFunc:     validateChildren,
+				DiffSuppressFunc: suppressChildrenDiff,
 			},
 			"child": {
 				Type:        schema.TypeSet,
@@ -82,88 +131,170 @@ func resourceAciRest() *schema.Resource {
 					},
 				},
 			},
+			"wait_for": {
+				Type:        schema.TypeList,
+				Description: "Wait for objects to reach an operational state after writing the object, e.g. a fabric node to become active. If neither `dn` nor `class_name` is provided, the object itself is queried.",
+				Optional:    true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"dn": {
+							Type:         schema.TypeString,
+							Description:  "Distinguished name of the object to be queried, e.g. topology/pod-1/node-101. If `class_name` is also provided, all objects of this class within the subtree are queried.",
+							Optional:     true,
+							ValidateFunc: validateDn,
+						},
+						"class_name": {
+							Type:        schema.TypeString,
+							Description: "Class name of the objects to be queried, e.g. fabricNode.",
+							Optional:    true,
+						},
+						"filter": {
+							Type:        schema.TypeString,
+							Description: "Query filter to select objects, e.g. `eq(fabricNode.serial,\"FDO12345678\")`.",
+							Optional:    true,
+						},
+						"attributes": {
+							Type:        schema.TypeMap,
+							Description: "Map of attributes and their expected values, e.g. `{fabricSt = \"active\"}`. All queried objects must have the expected values and at least one object must exist.",
+							Required:    true,
+							Elem:        &schema.Schema{Type: schema.TypeString},
+						},
+						"timeout": {
+							Type:         schema.TypeInt,
+							Description:  "Number of seconds to wait for the condition to be met.",
+							Optional:     true,
+							Default:      300,
+							ValidateFunc: validation.IntAtLeast(0),
+						},
+					},
+				},
+			},
+			"fault_check": {
+				Type:        schema.TypeList,
+				Description: "Check the object and its children for faults after writing it.",
+				Optional:    true,
+				MaxItems:    1,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"severity": {
+							Type:         schema.TypeString,
+							Description:  "Minimum severity of faults to be reported. Choices: `info`, `warning`, `minor`, `major`, `critical`.",
+							Optional:     true,
+							Default:      "major",
+							ValidateFunc: validation.StringInSlice(FaultSeverities[1:], false),
+						},
+						"timeout": {
+							Type:         schema.TypeInt,
+							Description:  "Number of seconds to wait for faults to be raised.",
+							Optional:     true,
+							Default:      10,
+							ValidateFunc: validation.IntAtLeast(0),
+						},
+						"warn_only": {
+							Type:        schema.TypeBool,
+							Description: "Report faults as warnings instead of failing the apply.",
+							Optional:    true,
+							Default:     false,
+						},
+					},
+				},
+			},
 		},
 	}
 }
 
-func getAciRest(d *schema.ResourceData, c *container.Container) diag.Diagnostics {
+func getAciRest(d *schema.ResourceData, meta interface{}, c *container.Container) diag.Diagnostics {
+	rules := meta.(apiClient).NormalizationRules
 	className := d.Get("class_name").(string)
 	dn := d.Get("dn").(string)
 	d.SetId(dn)
 
-	content := d.Get("content")
-	contentStrMap := toStrMap(content.(map[string]interface{}))
-	newContent := make(map[string]interface{})
-	restContent, ok := c.Search("imdata", className, "attributes").Index(0).Data().(map[string]interface{})
-
-	if !ok {
-		return diag.Errorf("Failed to retrieve REST payload for class: %s.", className)
+	obj, diags := decodeSingleObject(c, className)
+	if diags.HasError() {
+		return diags
 	}
 
-	for attr, value := range restContent {
-		// Ignore certain attributes
-		if !containsString(IgnoreAttr, attr) {
-			newContent[attr] = value.(string)
-		}
+	attributes := make(map[string]interface{})
+	for attr, value := range obj.Attributes {
+		attributes[attr] = value
 	}
+	d.Set("attributes", attributes)
 
-	for attr, value := range contentStrMap {
-		// Do not read/update write-only attributes, eg. 'childAction'
-		if containsString(WriteOnlyAttr, attr) {
-			newContent[attr] = value
-		}
-	}
-	d.Set("content", newContent)
+	d.Set("content", refreshContent(rules, className, toStrMap(d.Get("content").(map[string]interface{})), obj.Attributes))
+
+	setSensitiveContentHashes(d)
+
+	index := obj.childIndex()
 
 	newChildrenSet := make([]interface{}, 0, 1)
 	for _, child := range d.Get("child").(*schema.Set).List() {
 		newChildMap := make(map[string]interface{})
-		childRn := child.(map[string]interface{})["rn"].(string)
-		childClassName := child.(map[string]interface{})["class_name"].(string)
-		childContent := child.(map[string]interface{})["content"]
+		childMap, _ := child.(map[string]interface{})
+		childRn, _ := childMap["rn"].(string)
+		childClassName, _ := childMap["class_name"].(string)
+		childContent, _ := childMap["content"].(map[string]interface{})
 		newChildMap["rn"] = childRn
 		newChildMap["class_name"] = childClassName
-		// Loop over retrieved children
-		for _, rChild := range c.Search("imdata", className, "children").Index(0).Data().([]interface{}) {
-			for rChildClassName, rChildObject := range rChild.(map[string]interface{}) {
-				// Look for desired class
-				if rChildClassName == childClassName {
-					attrMap := rChildObject.(map[string]interface{})["attributes"].(map[string]interface{})
-					for attr, value := range attrMap {
-						// Find desired object by its rn
-						if attr == "rn" && value.(string) == childRn {
-							newChildContent := make(map[string]interface{})
-
-							for key := range toStrMap(childContent.(map[string]interface{})) {
-								newChildContent[key] = attrMap[key].(string)
-							}
-							newChildMap["content"] = newChildContent
-						}
-					}
-				}
-			}
+		// Find desired object by its rn
+		if rChild, ok := index[childRn]; ok && rChild.ClassName == childClassName {
+			newChildMap["content"] = refreshContent(rules, childClassName, toStrMap(childContent), rChild.Attributes)
 		}
 		newChildrenSet = append(newChildrenSet, newChildMap)
 	}
 	d.Set("child", newChildrenSet)
 
+	newChildren := make(map[string]interface{})
+	for rn, value := range d.Get("children").(map[string]interface{}) {
+		v, _ := value.(string)
+		child, err := parseRestChild(rn, v)
+		if err != nil {
+			return diag.FromErr(err)
+		}
+		rChild, ok := index[rn]
+		// Children which no longer exist are removed from state
+		if !ok || rChild.ClassName != child.ClassName {
+			continue
+		}
+		for key, value := range refreshContent(rules, child.ClassName, child.Content, rChild.Attributes) {
+			child.Content[key] = value.(string)
+		}
+		newChildren[rn] = child.String()
+	}
+	d.Set("children", newChildren)
+
 	return nil
 }
 
+// refreshContent returns the configured attributes with the values returned by the APIC.
+// Write-only attributes, e.g. 'childAction', and equivalent values, e.g. 'true' instead of 'yes',
+// are kept as configured.
+func refreshContent(rules []normalizationRule, className string, configured map[string]string, attributes map[string]string) map[string]interface{} {
+	content := make(map[string]interface{})
+	for attr, value := range configured {
+		if v, ok := attributes[attr]; ok && !containsString(WriteOnlyAttr, attr) && !normalizedEqual(rules, className, attr, v, value) {
+			content[attr] = v
+		} else {
+			content[attr] = value
+		}
+	}
+	return content
+}
+
 func resourceAciRestReadHelper(ctx context.Context, d *schema.ResourceData, meta interface{}, expectObject bool) diag.Diagnostics {
-	log.Printf("[DEBUG] %s: Beginning Read", d.Id())
+	ctx = logContext(ctx, meta)
+	tflog.Debug(ctx, "Beginning Read", map[string]interface{}{"id": d.Id()})
 
 	for attempts := 0; ; attempts++ {
 		getChildren := false
-		if len(d.Get("child").(*schema.Set).List()) > 0 {
+		if len(d.Get("child").(*schema.Set).List()) > 0 || len(d.Get("children").(map[string]interface{})) > 0 {
 			getChildren = true
 		}
-		cont, diags := ApicRest(d, meta, "GET", getChildren)
+		cont, diags := ApicRest(ctx, d, meta, "GET", getChildren, attempts)
 		if diags.HasError() {
-			if ok := backoff(attempts, meta.(apiClient).Retries); !ok {
+			if ok := backoff(ctx, attempts, meta.(apiClient).Retries); !ok {
 				return diags
 			}
-			log.Printf("[ERROR] Failed to read object: %s, retries: %v", diags[0].Summary, attempts)
+			tflog.SubsystemWarn(ctx, logRetry, "Failed to read object", map[string]interface{}{"error": diags[0].Summary, "attempt": attempts})
 			continue
 		}
 
@@ -173,63 +304,134 @@ func resourceAciRestReadHelper(ctx context.Context, d *schema.ResourceData, meta
 			return nil
 		}
 
-		diags = getAciRest(d, cont)
+		diags = getAciRest(d, meta, cont)
 		if !diags.HasError() {
 			break
 		}
-		if ok := backoff(attempts, meta.(apiClient).Retries); !ok {
+		if ok := backoff(ctx, attempts, meta.(apiClient).Retries); !ok {
 			return diags
 		}
-		log.Printf("[ERROR] Failed to decode response after reading object: %s, retries: %v", diags[0].Summary, attempts)
+		tflog.SubsystemWarn(ctx, logDecode, "Failed to decode response after reading object", map[string]interface{}{"error": diags[0].Summary, "attempt": attempts})
 	}
 
-	log.Printf("[DEBUG] %s: Read finished successfully", d.Id())
+	tflog.Debug(ctx, "Read finished successfully", map[string]interface{}{"id": d.Id()})
 	return nil
 }
 
 func resourceAciRestCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	if meta.(apiClient).IsReadOnly {
+		return readOnlyDiags("POST", d.Get("dn").(string))
+	}
+	if diags := setConfiguredContent(d); diags.HasError() {
+		return diags
+	}
+	if diags := setPayload(d, meta); diags.HasError() {
+		return diags
+	}
+	// Payloads are prepared from the raw configuration, therefore the values can be hashed before
+	// sending any requests to never persist them in state, even if the request fails
+	setSensitiveContentHashes(d)
 	if meta.(apiClient).IsMock {
 		d.SetId(d.Get("dn").(string))
 		return nil
 	}
 
-	log.Printf("[DEBUG] %s: Beginning Create", d.Id())
+	ctx = logContext(ctx, meta)
+	tflog.Debug(ctx, "Beginning Create", map[string]interface{}{"id": d.Id()})
 
+	var auditDiags diag.Diagnostics
 	for attempts := 0; ; attempts++ {
-		_, diags := ApicRest(d, meta, "POST", false)
+		_, diags := ApicRest(ctx, d, meta, "POST", false, attempts)
+		diags, failed := splitAuditLogDiags(diags)
+		auditDiags = append(auditDiags, failed...)
 		if !diags.HasError() {
 			break
 		}
-		if ok := backoff(attempts, meta.(apiClient).Retries); !ok {
-			return diags
+		if ok := backoff(ctx, attempts, meta.(apiClient).Retries); !ok {
+			return append(diags, auditDiags...)
 		}
-		log.Printf("[ERROR] Failed to create object: %s, retries: %v", diags[0].Summary, attempts)
+		tflog.SubsystemWarn(ctx, logRetry, "Failed to create object", map[string]interface{}{"error": diags[0].Summary, "attempt": attempts})
+	}
+	// The object is not added to state, the next apply sends the same request again
+	if auditDiags.HasError() {
+		return auditDiags
 	}
 
-	log.Printf("[DEBUG] %s: Create finished successfully", d.Id())
-	return resourceAciRestReadHelper(ctx, d, meta, true)
+	// Objects are not written in dry-run mode, the planned object is added to state so that dependent
+	// objects are processed and is removed again by the next refresh
+	if meta.(apiClient).DryRunPath != "" {
+		d.SetId(d.Get("dn").(string))
+		tflog.Debug(ctx, "Create written to dry-run file", map[string]interface{}{"id": d.Id()})
+		return dryRunDiags("POST", d.Get("dn").(string))
+	}
+
+	tflog.Debug(ctx, "Create finished successfully", map[string]interface{}{"id": d.Id()})
+	diags := resourceAciRestReadHelper(ctx, d, meta, true)
+	if diags.HasError() {
+		return diags
+	}
+	diags = append(diags, waitFor(ctx, d, meta)...)
+	if diags.HasError() {
+		return diags
+	}
+	return append(diags, faultCheck(ctx, d, meta)...)
 }
 
 func resourceAciRestUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	if meta.(apiClient).IsReadOnly {
+		return readOnlyDiags("POST", d.Get("dn").(string))
+	}
+	if diags := setConfiguredContent(d); diags.HasError() {
+		return diags
+	}
+	if diags := setPayload(d, meta); diags.HasError() {
+		return diags
+	}
+	// Payloads are prepared from the raw configuration, therefore the values can be hashed before
+	// sending any requests to never persist them in state, even if the request fails
+	setSensitiveContentHashes(d)
 	if meta.(apiClient).IsMock {
 		return nil
 	}
 
-	log.Printf("[DEBUG] %s: Beginning Update", d.Id())
+	ctx = logContext(ctx, meta)
+	tflog.Debug(ctx, "Beginning Update", map[string]interface{}{"id": d.Id()})
 
+	var auditDiags diag.Diagnostics
 	for attempts := 0; ; attempts++ {
-		_, diags := ApicRest(d, meta, "POST", false)
+		_, diags := ApicRest(ctx, d, meta, "POST", false, attempts)
+		diags, failed := splitAuditLogDiags(diags)
+		auditDiags = append(auditDiags, failed...)
 		if !diags.HasError() {
 			break
 		}
-		if ok := backoff(attempts, meta.(apiClient).Retries); !ok {
-			return diags
+		if ok := backoff(ctx, attempts, meta.(apiClient).Retries); !ok {
+			return append(diags, auditDiags...)
 		}
-		log.Printf("[ERROR] Failed to update object: %s, retries: %v", diags[0].Summary, attempts)
+		tflog.SubsystemWarn(ctx, logRetry, "Failed to update object", map[string]interface{}{"error": diags[0].Summary, "attempt": attempts})
+	}
+	// The object has been updated and the new configuration is stored in state
+	if auditDiags.HasError() {
+		return auditDiags
 	}
 
-	log.Printf("[DEBUG] %s: Update finished successfully", d.Id())
-	return resourceAciRestReadHelper(ctx, d, meta, true)
+	// Objects are not written in dry-run mode, the prior state is kept
+	if meta.(apiClient).DryRunPath != "" {
+		d.Partial(true)
+		tflog.Debug(ctx, "Update written to dry-run file", map[string]interface{}{"id": d.Id()})
+		return dryRunDiags("POST", d.Get("dn").(string))
+	}
+
+	tflog.Debug(ctx, "Update finished successfully", map[string]interface{}{"id": d.Id()})
+	diags := resourceAciRestReadHelper(ctx, d, meta, true)
+	if diags.HasError() {
+		return diags
+	}
+	diags = append(diags, waitFor(ctx, d, meta)...)
+	if diags.HasError() {
+		return diags
+	}
+	return append(diags, faultCheck(ctx, d, meta)...)
 }
 
 func resourceAciRestRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
@@ -241,46 +443,355 @@ func resourceAciRestRead(ctx context.Context, d *schema.ResourceData, meta inter
 }
 
 func resourceAciRestDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	if meta.(apiClient).IsReadOnly {
+		return readOnlyDiags("DELETE", d.Get("dn").(string))
+	}
 	if meta.(apiClient).IsMock {
 		d.SetId("")
 		return nil
 	}
 
-	log.Printf("[DEBUG] %s: Beginning Destroy", d.Id())
+	ctx = logContext(ctx, meta)
+	tflog.Debug(ctx, "Beginning Destroy", map[string]interface{}{"id": d.Id()})
 
+	var auditDiags diag.Diagnostics
 	for attempts := 0; ; attempts++ {
-		_, diags := ApicRest(d, meta, "DELETE", false)
+		_, diags := ApicRest(ctx, d, meta, "DELETE", false, attempts)
+		diags, failed := splitAuditLogDiags(diags)
+		auditDiags = append(auditDiags, failed...)
 		if !diags.HasError() {
 			break
 		}
-		if ok := backoff(attempts, meta.(apiClient).Retries); !ok {
-			return diags
+		if ok := backoff(ctx, attempts, meta.(apiClient).Retries); !ok {
+			return append(diags, auditDiags...)
 		}
-		log.Printf("[ERROR] Failed to delete object: %s, retries: %v", diags[0].Summary, attempts)
+		tflog.SubsystemWarn(ctx, logRetry, "Failed to delete object", map[string]interface{}{"error": diags[0].Summary, "attempt": attempts})
+	}
+	// The object has been deleted and is removed from state, even if the request was not recorded
+	if auditDiags.HasError() {
+		d.SetId("")
+		return auditDiags
+	}
+
+	// Objects are not deleted in dry-run mode, the apply fails to keep them in state
+	if meta.(apiClient).DryRunPath != "" {
+		tflog.Debug(ctx, "Destroy written to dry-run file", map[string]interface{}{"id": d.Id()})
+		return dryRunDeleteDiags(d.Get("dn").(string))
 	}
 
 	d.SetId("")
-	log.Printf("[DEBUG] %s: Destroy finished successfully", d.Id())
+	tflog.Debug(ctx, "Destroy finished successfully", map[string]interface{}{"id": d.Id()})
 	return nil
 }
 
 func resourceAciRestImport(ctx context.Context, d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
-	log.Printf("[DEBUG] %s: Beginning Import", d.Id())
+	ctx = logContext(ctx, meta)
+	tflog.Debug(ctx, "Beginning Import", map[string]interface{}{"id": d.Id()})
 
-	parts := strings.SplitN(d.Id(), ":", 2)
+	className, dn, childClasses, contentKeys, err := parseImportId(d.Id())
+	if err != nil {
+		return nil, err
+	}
 
-	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
-		return nil, fmt.Errorf("Unexpected format of ID (%s), expected class_name:dn", d.Id())
+	if className == "" {
+		var diags diag.Diagnostics
+		if className, diags = discoverClassName(ctx, meta, dn); diags.HasError() {
+			return nil, fmt.Errorf("Could not discover class name when importing: %s", diags[0].Summary)
+		}
 	}
 
-	d.Set("dn", parts[1])
-	d.Set("class_name", parts[0])
-	d.SetId(parts[1])
+	d.Set("dn", dn)
+	d.Set("class_name", className)
+	d.SetId(dn)
+
+	if diags := importAciRestContent(ctx, d, meta, childClasses, contentKeys); diags.HasError() {
+		return nil, fmt.Errorf("Could not read configuration when importing: %s", diags[0].Summary)
+	}
 
 	if diags := resourceAciRestReadHelper(ctx, d, meta, true); diags.HasError() {
 		return nil, fmt.Errorf("Could not read object when importing: %s", diags[0].Summary)
 	}
 
-	log.Printf("[DEBUG] %s: Import finished successfully", d.Id())
+	cont, diags := importPayload(d, meta)
+	if diags.HasError() {
+		return nil, fmt.Errorf("Could not prepare payload when importing: %s", diags[0].Summary)
+	}
+	d.Set("payload", cont.String())
+
+	tflog.Debug(ctx, "Import finished successfully", map[string]interface{}{"id": d.Id()})
 	return []*schema.ResourceData{d}, nil
 }
+
+// Class names consist of a lowercase package prefix and a capitalized name, e.g. 'fvTenant' or 'l3extOut'
+var classNameRegexp = regexp.MustCompile(`^[a-z][a-z0-9]*[A-Z][A-Za-z0-9]*$`)
+
+// Content keys are attribute names, optionally prefixed with the class of a child, e.g. 'fvCtx.name'
+var contentKeyRegexp = regexp.MustCompile(`^([a-z][a-z0-9]*[A-Z][A-Za-z0-9]*\.)?[A-Za-z][A-Za-z0-9]*$`)
+
+// importId returns the import ID of an object, see parseImportId.
+func importId(className string, dn string, childClasses []string, contentKeys []string) string {
+	return className + ":" + dn + ":" + strings.Join(childClasses, ",") + ":" + strings.Join(contentKeys, ",")
+}
+
+// parseImportId parses an import ID of the format [class_name:]dn[:child_classes[:content_keys]].
+// Distinguished names may contain colons outside of brackets, e.g. of MAC addresses, therefore
+// the lists are parsed from the right and only if they consist of valid class names and keys.
+func parseImportId(id string) (className string, dn string, childClasses []string, contentKeys []string, err error) {
+	parts := splitOutsideBrackets(id, ':')
+	if len(parts) > 1 && classNameRegexp.MatchString(parts[0]) {
+		className = parts[0]
+		parts = parts[1:]
+	}
+	for lists := 2; lists > 0; lists-- {
+		if len(parts) <= lists {
+			continue
+		}
+		tail := parts[len(parts)-lists:]
+		if !isImportList(tail[0], classNameRegexp) || (lists > 1 && !isImportList(tail[1], contentKeyRegexp)) {
+			continue
+		}
+		dn = strings.Join(parts[:len(parts)-lists], ":")
+		if _, err := parseDn(dn); err != nil {
+			continue
+		}
+		childClasses = splitImportList(tail[0])
+		if lists > 1 {
+			contentKeys = splitImportList(tail[1])
+		}
+		return className, dn, childClasses, contentKeys, nil
+	}
+	dn = strings.Join(parts, ":")
+	if dn == "" {
+		return "", "", nil, nil, fmt.Errorf("Unexpected format of ID (%s), expected [class_name:]dn[:child_classes[:content_keys]]", id)
+	}
+	if _, err := parseDn(dn); err != nil {
+		return "", "", nil, nil, err
+	}
+	return className, dn, nil, nil, nil
+}
+
+// isImportList returns true if s is empty or a comma separated list of values matching re.
+func isImportList(s string, re *regexp.Regexp) bool {
+	for _, v := range splitImportList(s) {
+		if !re.MatchString(v) {
+			return false
+		}
+	}
+	return true
+}
+
+func splitImportList(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(s, ",")
+}
+
+// importAciRestContent populates content with the given keys of the object and the child set with
+// all existing children of the given classes and their keys. Without keys, no attributes are
+// tracked until they are configured, see contentChanged.
+func importAciRestContent(ctx context.Context, d *schema.ResourceData, meta interface{}, childClasses []string, contentKeys []string) diag.Diagnostics {
+	dn := d.Get("dn").(string)
+	className := d.Get("class_name").(string)
+	query := make([]string, 0)
+	if len(childClasses) > 0 {
+		query = append(query, "rsp-subtree=children", "rsp-subtree-class="+strings.Join(childClasses, ","))
+	}
+	if !containsString(FullClasses, className) {
+		query = append(query, "rsp-prop-include=config-only")
+	}
+	path := dnUrlPath(dn)
+	if len(query) > 0 {
+		path += "?" + strings.Join(query, "&")
+	}
+
+	var cont *container.Container
+	for attempts := 0; ; attempts++ {
+		var diags diag.Diagnostics
+		cont, diags = apicRestRequest(logAttempt(ctx, attempts), meta, "GET", path, nil)
+		if !diags.HasError() {
+			break
+		}
+		if ok := backoff(ctx, attempts, meta.(apiClient).Retries); !ok {
+			return diags
+		}
+		tflog.SubsystemWarn(ctx, logRetry, "Failed to read object", map[string]interface{}{"error": diags[0].Summary, "attempt": attempts})
+	}
+	if cont == nil {
+		return diag.Errorf("Object %s not found", dn)
+	}
+
+	obj, diags := decodeSingleObject(cont, className)
+	if diags.HasError() {
+		return diags
+	}
+	d.Set("content", importContent(obj.Attributes, filterContentKeys(contentKeys, "")))
+
+	if len(childClasses) == 0 {
+		return nil
+	}
+
+	childrenSet := make([]interface{}, 0, 1)
+	for _, child := range obj.Children {
+		if !containsString(childClasses, child.ClassName) {
+			continue
+		}
+		childrenSet = append(childrenSet, map[string]interface{}{
+			"rn":         child.rn(),
+			"class_name": child.ClassName,
+			"content":    importContent(child.Attributes, filterContentKeys(contentKeys, child.ClassName)),
+		})
+	}
+	d.Set("child", childrenSet)
+	return nil
+}
+
+// importContent returns the attributes with the given keys which are imported into content.
+func importContent(attributes map[string]string, keys []string) map[string]interface{} {
+	content := make(map[string]interface{})
+	for attr, value := range attributes {
+		if containsString(IgnoreAttr, attr) || !containsString(keys, attr) {
+			continue
+		}
+		content[attr] = value
+	}
+	return content
+}
+
+// filterContentKeys returns the keys which apply to the object itself (className is empty)
+// or to children of the given class, which are prefixed with the class name, e.g. 'fvCtx.name'.
+func filterContentKeys(contentKeys []string, className string) []string {
+	keys := make([]string, 0)
+	for _, key := range contentKeys {
+		parts := strings.SplitN(key, ".", 2)
+		if className == "" && len(parts) == 1 {
+			keys = append(keys, key)
+		} else if className != "" && len(parts) == 2 && parts[0] == className {
+			keys = append(keys, parts[1])
+		}
+	}
+	return keys
+}
+
+func resourceAciRestCustomizeDiff(ctx context.Context, d *schema.ResourceDiff, meta interface{}) error {
+	for _, child := range d.Get("child").(*schema.Set).List() {
+		childRn := child.(map[string]interface{})["rn"].(string)
+		if _, ok := d.Get("children").(map[string]interface{})[childRn]; ok {
+			return fmt.Errorf("Child %s must not be configured in both 'child' and 'children'", childRn)
+		}
+	}
+
+	cl, ok := meta.(apiClient)
+	if err := setNormalizedContent(d, cl.NormalizationRules); err != nil {
+		return err
+	}
+	if err := setSensitiveSalt(d); err != nil {
+		return err
+	}
+	changed := d.HasChanges("dn", "class_name", "child", "sensitive_content_wo_version") || contentChanged(d, cl.NormalizationRules) || childrenChanged(d) || sensitiveContentChanged(d)
+	if ok && cl.IsReadOnly && (d.Id() == "" || changed) {
+		return fmt.Errorf("Object %s cannot be created or updated, the provider is configured with read_only", d.Get("dn").(string))
+	}
+
+	// Any change to the object is likely to change its server-side attributes as well
+	if d.Id() != "" && changed {
+		if err := d.SetNewComputed("attributes"); err != nil {
+			return err
+		}
+	}
+
+	if ok && (d.Id() == "" || changed) {
+		// The payload is only known if all attributes it is prepared from are known
+		for _, attr := range []string{"class_name", "content", "child", "children", "sensitive_content", "sensitive_content_wo"} {
+			if value, diags := d.GetRawConfigAt(cty.GetAttrPath(attr)); diags.HasError() || !value.IsWhollyKnown() {
+				return d.SetNewComputed("payload")
+			}
+		}
+		cont, diags := restPayload(d, cl, true)
+		if diags.HasError() {
+			return fmt.Errorf("%s", diags[0].Summary)
+		}
+		if err := d.SetNew("payload", cont.String()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// contentChanged returns true if a configured attribute of 'content' is not equivalent to the value
+// in state. Attributes which are not configured are ignored, see suppressNormalizedDiff. Configured
+// attributes which are not in state, e.g. after importing an object without keys, are compared to
+// the attributes read from the APIC.
+func contentChanged(d *schema.ResourceDiff, rules []normalizationRule) bool {
+	if !d.NewValueKnown("content") {
+		return true
+	}
+	className := d.Get("class_name").(string)
+	o, n := d.GetChange("content")
+	oldContent := toStrMap(o.(map[string]interface{}))
+	a, _ := d.GetChange("attributes")
+	attributes := toStrMap(a.(map[string]interface{}))
+	for attr, value := range toStrMap(n.(map[string]interface{})) {
+		v, ok := oldContent[attr]
+		if !ok && d.Id() != "" {
+			v, ok = attributes[attr]
+		}
+		if !ok || !normalizedEqual(rules, className, attr, v, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// childrenChanged returns true if a child of 'children' is added, removed or not equivalent to the
+// child in state, see suppressChildrenDiff.
+func childrenChanged(d *schema.ResourceDiff) bool {
+	if !d.NewValueKnown("children") {
+		return true
+	}
+	o, n := d.GetChange("children")
+	oldChildren := toStrMap(o.(map[string]interface{}))
+	newChildren := toStrMap(n.(map[string]interface{}))
+	if len(oldChildren) != len(newChildren) {
+		return true
+	}
+	for rn, value := range newChildren {
+		if v, ok := oldChildren[rn]; !ok || !childEqual(rn, v, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// setConfiguredContent removes attributes which are no longer configured from 'content'.
+func setConfiguredContent(d *schema.ResourceData) diag.Diagnostics {
+	content, diags := configuredContent(d)
+	if diags.HasError() {
+		return diags
+	}
+	d.Set("content", content)
+	return nil
+}
+
+// setPayload stores the payload sent to the APIC, which must match the payload planned by
+// resourceAciRestCustomizeDiff.
+func setPayload(d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	cont, diags := restPayload(d, meta, true)
+	if diags.HasError() {
+		return diags
+	}
+	d.Set("payload", cont.String())
+	return nil
+}
+
+func validateChildren(val interface{}, key string) (warns []string, errs []error) {
+	for rn, value := range val.(map[string]interface{}) {
+		if value.(string) == UnknownValue {
+			continue
+		}
+		if _, err := parseRestChild(rn, value.(string)); err != nil {
+			errs = append(errs, fmt.Errorf("%q: %s", key, err))
+		}
+	}
+	return
+}
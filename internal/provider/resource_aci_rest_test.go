@@ -1,11 +1,18 @@
 package provider
 
 import (
+	"context"
 	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"regexp"
 	"strings"
 	"testing"
 
 	"github.com/ciscoecosystem/aci-go-client/models"
+	ctyjson "github.com/hashicorp/go-cty/cty/json"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/acctest"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
@@ -23,18 +30,40 @@ func TestAccAciRest_tenant(t *testing.T) {
 				Config: testAccAciRestConfig_tenant(name, "Create description"),
 				Check: resource.ComposeTestCheckFunc(
 					testAccCheckAciRestObject("aci_rest.fvTenant"),
+					resource.TestCheckResourceAttr("aci_rest.fvTenant", "attributes.name", name),
+					resource.TestCheckResourceAttr("aci_rest.fvTenant", "attributes.dn", "uni/tn-"+name),
+					resource.TestCheckResourceAttr("aci_rest.fvTenant", "content.%", "3"),
+					resource.TestCheckResourceAttr("aci_rest.fvTenant", "payload", fmt.Sprintf(`{"fvTenant":{"attributes":{"annotation":"orchestrator:terraform","descr":"Create description","name":"%s","nameAlias":"Testacc_Tenant"},"children":[]}}`, name)),
 				),
 			},
 			{
 				ResourceName:      "aci_rest.fvTenant",
 				ImportState:       true,
-				ImportStateId:     "fvTenant:uni/tn-" + name,
+				ImportStateId:     "fvTenant:uni/tn-" + name + "::name,descr,nameAlias",
 				ImportStateVerify: true,
 			},
+			{
+				ResourceName:      "aci_rest.fvTenant",
+				ImportState:       true,
+				ImportStateId:     "uni/tn-" + name + "::name,descr,nameAlias",
+				ImportStateVerify: true,
+			},
+			{
+				// Importing without keys tracks no attributes, which are compared to the object when planning
+				ResourceName:       "aci_rest.fvTenant",
+				ImportState:        true,
+				ImportStateId:      "fvTenant:uni/tn-" + name,
+				ImportStatePersist: true,
+			},
+			{
+				Config:   testAccAciRestConfig_tenant(name, "Create description"),
+				PlanOnly: true,
+			},
 			{
 				Config: testAccAciRestConfig_tenant(name, "Updated description"),
 				Check: resource.ComposeTestCheckFunc(
 					testAccCheckAciRestObject("aci_rest.fvTenant"),
+					resource.TestCheckResourceAttr("aci_rest.fvTenant", "content.%", "3"),
 				),
 			},
 		},
@@ -56,9 +85,19 @@ func TestAccAciRest_connPref(t *testing.T) {
 			{
 				ResourceName:      "aci_rest.mgmtConnectivityPrefs",
 				ImportState:       true,
-				ImportStateId:     "mgmtConnectivityPrefs:uni/fabric/connectivityPrefs",
+				ImportStateId:     "mgmtConnectivityPrefs:uni/fabric/connectivityPrefs::interfacePref",
 				ImportStateVerify: true,
 			},
+			{
+				ResourceName:       "aci_rest.mgmtConnectivityPrefs",
+				ImportState:        true,
+				ImportStateId:      "mgmtConnectivityPrefs:uni/fabric/connectivityPrefs",
+				ImportStatePersist: true,
+			},
+			{
+				Config:   testAccAciRestConfig_connPref("ooband"),
+				PlanOnly: true,
+			},
 			{
 				Config: testAccAciRestConfig_connPref("inband"),
 				Check: resource.ComposeTestCheckFunc(
@@ -107,10 +146,576 @@ func TestAccAciRest_tenantVrf(t *testing.T) {
 					resource.TestCheckResourceAttr("aci_rest.fvTenant", "child.0.content.name", name),
 				),
 			},
+			{
+				ResourceName:      "aci_rest.fvTenant",
+				ImportState:       true,
+				ImportStateId:     "fvTenant:uni/tn-" + name + ":fvCtx:name,fvCtx.name",
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
+
+func TestAccAciRest_tenantChildren(t *testing.T) {
+	name := acctest.RandStringFromCharSet(10, acctest.CharSetAlphaNum)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: providerFactories,
+		CheckDestroy:      testAccCheckAciRestDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccAciRestConfig_tenantChildren(name, "Create description"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("aci_rest.fvTenant", "children.ctx-"+name, `{"class_name":"fvCtx","content":{"descr":"Create description","name":"`+name+`"}}`),
+				),
+			},
+			{
+				Config: testAccAciRestConfig_tenantChildren(name, "Updated description"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("aci_rest.fvTenant", "children.ctx-"+name, `{"class_name":"fvCtx","content":{"descr":"Updated description","name":"`+name+`"}}`),
+				),
+			},
 		},
 	})
 }
 
+func TestAccAciRest_sensitiveContent(t *testing.T) {
+	name := acctest.RandStringFromCharSet(10, acctest.CharSetAlpha)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: providerFactories,
+		CheckDestroy:      testAccCheckAciRestDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccAciRestConfig_sensitiveContent(name, "Cisco123!Secret"),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckSensitiveHash("aci_rest.aaaUser", "pwd", "Cisco123!Secret"),
+				),
+			},
+			{
+				Config:   testAccAciRestConfig_sensitiveContent(name, "Cisco123!Secret"),
+				PlanOnly: true,
+			},
+			{
+				Config: testAccAciRestConfig_sensitiveContent(name, "Cisco456!Secret"),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckSensitiveHash("aci_rest.aaaUser", "pwd", "Cisco456!Secret"),
+				),
+			},
+			{
+				Config:   testAccAciRestConfig_sensitiveContent(name, "Cisco456!Secret"),
+				PlanOnly: true,
+			},
+			{
+				Config:   testAccAciRestConfig_readOnly() + testAccAciRestConfig_sensitiveContent(name, "Cisco456!Secret"),
+				PlanOnly: true,
+			},
+		},
+	})
+}
+
+func TestAccAciRest_dryRun(t *testing.T) {
+	name := acctest.RandStringFromCharSet(10, acctest.CharSetAlphaNum)
+	path := filepath.Join(t.TempDir(), "requests.jsonl")
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: providerFactories,
+		CheckDestroy:      testAccCheckAciRestDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccAciRestConfig_dryRun(path, name),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckDryRunRequests(path, "uni/tn-"+name, "uni/tn-"+name+"/ctx-"+name, "uni/tn-"+name+"/BD-"+name),
+				),
+				// Created objects do not exist and are planned again after the refresh
+				ExpectNonEmptyPlan: true,
+			},
+		},
+	})
+}
+
+func TestAccAciRest_faultCheck(t *testing.T) {
+	name := acctest.RandStringFromCharSet(10, acctest.CharSetAlphaNum)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: providerFactories,
+		CheckDestroy:      testAccCheckAciRestDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccAciRestConfig_faultCheck(name),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckAciRestObject("aci_rest.fvTenant"),
+					resource.TestCheckResourceAttr("aci_rest.fvTenant", "fault_check.0.severity", "major"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccAciRest_waitFor(t *testing.T) {
+	name := acctest.RandStringFromCharSet(10, acctest.CharSetAlphaNum)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: providerFactories,
+		CheckDestroy:      testAccCheckAciRestDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccAciRestConfig_waitFor(name),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckAciRestObject("aci_rest.fvTenant"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccAciRest_readOnly(t *testing.T) {
+	name := acctest.RandStringFromCharSet(10, acctest.CharSetAlphaNum)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { testAccPreCheck(t) },
+		ProviderFactories: providerFactories,
+		CheckDestroy:      testAccCheckAciRestDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccAciRestConfig_tenant(name, "Create description"),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckAciRestObject("aci_rest.fvTenant"),
+				),
+			},
+			{
+				Config:   testAccAciRestConfig_readOnly() + testAccAciRestConfig_tenant(name, "Create description"),
+				PlanOnly: true,
+			},
+			{
+				Config:      testAccAciRestConfig_readOnly() + testAccAciRestConfig_tenant(name, "Updated description"),
+				ExpectError: regexp.MustCompile("read_only"),
+			},
+			{
+				Config: testAccAciRestConfig_tenant(name, "Create description"),
+			},
+		},
+	})
+}
+
+// testAciRestDiff plans a change from the given state, which is empty for new objects, to a JSON configuration.
+func testAciRestDiff(t *testing.T, config string, attributes map[string]string, meta interface{}) (*terraform.InstanceDiff, error) {
+	r := resourceAciRest()
+	rawConfig, err := ctyjson.Unmarshal([]byte(config), r.CoreConfigSchema().ImpliedType())
+	if err != nil {
+		t.Fatalf("invalid config: %s", err)
+	}
+	state := &terraform.InstanceState{ID: attributes["id"], Attributes: attributes, RawConfig: rawConfig}
+	return r.SimpleDiff(context.Background(), state, terraform.NewResourceConfigShimmed(rawConfig, r.CoreConfigSchema()), meta)
+}
+
+// testAciRestApply plans and applies a change, returning the new state.
+func testAciRestApply(t *testing.T, config string, attributes map[string]string, meta interface{}) (*terraform.InstanceState, diag.Diagnostics) {
+	diff, err := testAciRestDiff(t, config, attributes, meta)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	state := &terraform.InstanceState{ID: attributes["id"], Attributes: attributes, RawConfig: diff.RawConfig}
+	return resourceAciRest().Apply(context.Background(), state, diff, meta)
+}
+
+func TestResourceAciRestDiff(t *testing.T) {
+	state := map[string]string{
+		"id":               "uni/tn-EXAMPLE",
+		"dn":               "uni/tn-EXAMPLE",
+		"class_name":       "fvTenant",
+		"content.%":        "3",
+		"content.name":     "EXAMPLE",
+		"content.descr":    "Description",
+		"content.ownerKey": "",
+		"attributes.%":     "1",
+		"attributes.name":  "EXAMPLE",
+	}
+
+	// State of earlier versions contains all attributes of the object
+	diff, err := testAciRestDiff(t, `{"dn": "uni/tn-EXAMPLE", "class_name": "fvTenant", "content": {"name": "EXAMPLE"}}`, state, apiClient{})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if !diff.Empty() {
+		t.Fatalf("expected empty diff for attributes which are not configured, got %v", diff.Attributes)
+	}
+
+	diff, err = testAciRestDiff(t, `{"dn": "uni/tn-EXAMPLE", "class_name": "fvTenant", "content": {"name": "EXAMPLE", "descr": "Updated"}}`, state, apiClient{})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if a := diff.Attributes["content.descr"]; a == nil || a.New != "Updated" {
+		t.Fatalf("expected diff of content.descr, got %v", diff.Attributes)
+	}
+	if a := diff.Attributes["content.ownerKey"]; a != nil {
+		t.Fatalf("expected no diff of content.ownerKey, got %v", a)
+	}
+	if a := diff.Attributes["payload"]; a == nil || a.New != `{"fvTenant":{"attributes":{"descr":"Updated","name":"EXAMPLE"},"children":[]}}` {
+		t.Fatalf("expected payload with configured attributes only, got %v", a)
+	}
+}
+
+func TestResourceAciRestDiffImported(t *testing.T) {
+	// State of an object imported without keys does not track any attributes
+	state := map[string]string{
+		"id":               "uni/tn-EXAMPLE",
+		"dn":               "uni/tn-EXAMPLE",
+		"class_name":       "fvTenant",
+		"content.%":        "0",
+		"attributes.%":     "2",
+		"attributes.name":  "EXAMPLE",
+		"attributes.descr": "Description",
+		"payload":          `{"fvTenant":{"attributes":{},"children":[]}}`,
+	}
+
+	diff, err := testAciRestDiff(t, `{"dn": "uni/tn-EXAMPLE", "class_name": "fvTenant", "content": {"name": "EXAMPLE", "descr": "Description"}}`, state, apiClient{})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if !diff.Empty() {
+		t.Fatalf("expected empty diff for configured attributes matching the object, got %v", diff.Attributes)
+	}
+
+	diff, err = testAciRestDiff(t, `{"dn": "uni/tn-EXAMPLE", "class_name": "fvTenant", "content": {"name": "EXAMPLE", "descr": "Updated"}}`, state, apiClient{})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if a := diff.Attributes["content.descr"]; a == nil || a.New != "Updated" {
+		t.Fatalf("expected diff of content.descr, got %v", diff.Attributes)
+	}
+	if a := diff.Attributes["content.name"]; a != nil {
+		t.Fatalf("expected no diff of content.name, got %v", a)
+	}
+	if a := diff.Attributes["payload"]; a == nil || a.New != `{"fvTenant":{"attributes":{"descr":"Updated","name":"EXAMPLE"},"children":[]}}` {
+		t.Fatalf("expected payload with configured attributes, got %v", a)
+	}
+}
+
+func TestResourceAciRestNormalization(t *testing.T) {
+	state := map[string]string{
+		"id":               "uni/tn-EXAMPLE/BD-BD1",
+		"dn":               "uni/tn-EXAMPLE/BD-BD1",
+		"class_name":       "fvBD",
+		"content.%":        "2",
+		"content.name":     "BD1",
+		"content.arpFlood": "yes",
+		"attributes.%":     "1",
+		"attributes.name":  "BD1",
+	}
+	config := `{"dn": "uni/tn-EXAMPLE/BD-BD1", "class_name": "fvBD", "content": {"name": "bd1", "arpFlood": "true"}}`
+
+	diff, err := testAciRestDiff(t, config, state, apiClient{})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if a := diff.Attributes["content.arpFlood"]; a != nil {
+		t.Fatalf("expected no diff of equivalent value, got %v", a)
+	}
+	if a := diff.Attributes["content.name"]; a == nil || a.New != "bd1" {
+		t.Fatalf("expected diff of content.name without custom rules, got %v", diff.Attributes)
+	}
+
+	rules := []normalizationRule{{ClassName: "fvBD", Attribute: "name", Rule: "case_insensitive"}}
+	diff, err = testAciRestDiff(t, config, state, apiClient{NormalizationRules: rules})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if !diff.Empty() {
+		t.Fatalf("expected empty diff with custom rules, got %v", diff.Attributes)
+	}
+}
+
+func TestResourceAciRestChildren(t *testing.T) {
+	state := map[string]string{
+		"id":                "uni/tn-EXAMPLE",
+		"dn":                "uni/tn-EXAMPLE",
+		"class_name":        "fvTenant",
+		"content.%":         "1",
+		"content.name":      "EXAMPLE",
+		"children.%":        "2",
+		"children.BD-BD1":   `{"class_name":"fvBD","content":{"arpFlood":"yes","name":"BD1"}}`,
+		"children.ctx-VRF1": `{"class_name":"fvCtx","content":{"name":"VRF1"}}`,
+		"attributes.%":      "1",
+		"attributes.name":   "EXAMPLE",
+	}
+
+	// Documents with a different order of keys and equivalent values
+	diff, err := testAciRestDiff(t, `{"dn": "uni/tn-EXAMPLE", "class_name": "fvTenant", "content": {"name": "EXAMPLE"}, "children": {
+		"BD-BD1": "{\"content\": {\"name\": \"BD1\", \"arpFlood\": \"true\"}, \"class_name\": \"fvBD\"}",
+		"ctx-VRF1": "{\"class_name\": \"fvCtx\", \"content\": {\"name\": \"VRF1\"}}"
+	}}`, state, apiClient{})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if !diff.Empty() {
+		t.Fatalf("expected empty diff for equivalent children, got %v", diff.Attributes)
+	}
+
+	diff, err = testAciRestDiff(t, `{"dn": "uni/tn-EXAMPLE", "class_name": "fvTenant", "content": {"name": "EXAMPLE"}, "children": {
+		"BD-BD1": "{\"class_name\": \"fvBD\", \"content\": {\"name\": \"BD1\", \"arpFlood\": \"no\"}}",
+		"ctx-VRF1": "{\"class_name\": \"fvCtx\", \"content\": {\"name\": \"VRF1\"}}"
+	}}`, state, apiClient{})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if a := diff.Attributes["children.BD-BD1"]; a == nil {
+		t.Fatalf("expected diff of children.BD-BD1, got %v", diff.Attributes)
+	}
+	if a := diff.Attributes["children.ctx-VRF1"]; a != nil {
+		t.Fatalf("expected no diff of children.ctx-VRF1, got %v", a)
+	}
+	if a := diff.Attributes["payload"]; a == nil || !strings.Contains(a.New, `"arpFlood":"no"`) {
+		t.Fatalf("expected payload with changed child, got %v", a)
+	}
+}
+
+func TestResourceAciRestSensitiveContent(t *testing.T) {
+	config := `{"dn": "uni/userext/user-EXAMPLE", "class_name": "aaaUser", "content": {"name": "EXAMPLE"}, "sensitive_content": {"pwd": "%s"}}`
+	state, diags := testAciRestApply(t, fmt.Sprintf(config, "Cisco123!Secret"), nil, apiClient{IsMock: true})
+	if diags.HasError() {
+		t.Fatalf("unexpected error: %s", diags[0].Summary)
+	}
+	salt := state.Attributes["sensitive_salt"]
+	if salt == "" {
+		t.Fatalf("expected salt in state")
+	}
+	if v := state.Attributes["sensitive_content.pwd"]; v != hashSensitive(salt, "Cisco123!Secret") {
+		t.Fatalf("expected hash of sensitive value in state, got %s", v)
+	}
+	// Equal values of different resources have different hashes
+	other, diags := testAciRestApply(t, fmt.Sprintf(config, "Cisco123!Secret"), nil, apiClient{IsMock: true})
+	if diags.HasError() {
+		t.Fatalf("unexpected error: %s", diags[0].Summary)
+	}
+	if other.Attributes["sensitive_content.pwd"] == state.Attributes["sensitive_content.pwd"] {
+		t.Fatalf("expected different hashes for different salts")
+	}
+	// Resources without sensitive values have no salt, so that their imported state matches
+	plain, diags := testAciRestApply(t, `{"dn": "uni/userext/user-EXAMPLE", "class_name": "aaaUser", "content": {"name": "EXAMPLE"}}`, nil, apiClient{IsMock: true})
+	if diags.HasError() || plain.Attributes["sensitive_salt"] != "" {
+		t.Fatalf("expected no salt without sensitive values, got %v", diags)
+	}
+	state.Attributes["attributes.%"] = "1"
+	state.Attributes["attributes.name"] = "EXAMPLE"
+
+	diff, err := testAciRestDiff(t, fmt.Sprintf(config, "Cisco123!Secret"), state.Attributes, apiClient{})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if !diff.Empty() {
+		t.Fatalf("expected empty diff for unchanged sensitive value, got %v", diff.Attributes)
+	}
+
+	diff, err = testAciRestDiff(t, fmt.Sprintf(config, "Cisco456!Secret"), state.Attributes, apiClient{})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if a := diff.Attributes["payload"]; a == nil || !strings.Contains(a.New, hashSensitive(salt, "Cisco456!Secret")) {
+		t.Fatalf("expected payload with hash of changed sensitive value, got %v", a)
+	}
+
+	state, diags = testAciRestApply(t, fmt.Sprintf(config, "Cisco456!Secret"), state.Attributes, apiClient{IsMock: true})
+	if diags.HasError() {
+		t.Fatalf("unexpected error: %s", diags[0].Summary)
+	}
+	if v := state.Attributes["sensitive_content.pwd"]; v != hashSensitive(salt, "Cisco456!Secret") || state.Attributes["sensitive_salt"] != salt {
+		t.Fatalf("expected hash of sensitive value with unchanged salt in state, got %s", v)
+	}
+}
+
+func TestResourceAciRestReadOnly(t *testing.T) {
+	state := map[string]string{
+		"id":                    "uni/userext/user-EXAMPLE",
+		"dn":                    "uni/userext/user-EXAMPLE",
+		"class_name":            "aaaUser",
+		"content.%":             "2",
+		"content.name":          "EXAMPLE",
+		"content.descr":         "Description",
+		"sensitive_content.%":   "1",
+		"sensitive_content.pwd": hashSensitive("salt", "Cisco123!Secret"),
+		"sensitive_salt":        "salt",
+		"attributes.%":          "1",
+		"attributes.name":       "EXAMPLE",
+	}
+	config := `{"dn": "uni/userext/user-EXAMPLE", "class_name": "aaaUser", "content": {"name": "EXAMPLE"}, "sensitive_content": {"pwd": "%s"}}`
+	meta := apiClient{IsReadOnly: true}
+
+	diff, err := testAciRestDiff(t, fmt.Sprintf(config, "Cisco123!Secret"), state, meta)
+	if err != nil {
+		t.Fatalf("unexpected error for unchanged object: %s", err)
+	}
+	if !diff.Empty() {
+		t.Fatalf("expected empty diff, got %v", diff.Attributes)
+	}
+
+	if _, err := testAciRestDiff(t, fmt.Sprintf(config, "Cisco456!Secret"), state, meta); err == nil || !strings.Contains(err.Error(), "read_only") {
+		t.Fatalf("expected read_only error for changed object, got %v", err)
+	}
+	if _, err := testAciRestDiff(t, fmt.Sprintf(config, "Cisco123!Secret"), nil, meta); err == nil || !strings.Contains(err.Error(), "read_only") {
+		t.Fatalf("expected read_only error for new object, got %v", err)
+	}
+}
+
+func TestRefreshContent(t *testing.T) {
+	configured := map[string]string{"name": "EXAMPLE", "descr": "Old", "childAction": "deleteAll", "status": "created", "isAttrBasedEPg": "true", "pwd": "secret"}
+	attributes := map[string]string{"name": "EXAMPLE", "descr": "New", "childAction": "", "status": "", "isAttrBasedEPg": "yes"}
+	expected := map[string]interface{}{"name": "EXAMPLE", "descr": "New", "childAction": "deleteAll", "status": "created", "isAttrBasedEPg": "true", "pwd": "secret"}
+	if content := refreshContent(nil, "fvAEPg", configured, attributes); !reflect.DeepEqual(content, expected) {
+		t.Fatalf("expected %v, got %v", expected, content)
+	}
+}
+
+func TestParseImportId(t *testing.T) {
+	cases := []struct {
+		id                        string
+		className, dn             string
+		childClasses, contentKeys []string
+	}{
+		{"fvTenant:uni/tn-X", "fvTenant", "uni/tn-X", nil, nil},
+		{"uni/tn-X", "", "uni/tn-X", nil, nil},
+		{"fvTenant:uni/tn-X:fvCtx,fvBD:name,fvCtx.name", "fvTenant", "uni/tn-X", []string{"fvCtx", "fvBD"}, []string{"name", "fvCtx.name"}},
+		{"uni/tn-X::name", "", "uni/tn-X", nil, []string{"name"}},
+		{"uni:fvTenant", "", "uni", []string{"fvTenant"}, nil},
+		{"fvStCEp:uni/tn-X/ap-A/epg-B/stcep-00:50:56:AA:BB:CC-type-silent-host", "fvStCEp", "uni/tn-X/ap-A/epg-B/stcep-00:50:56:AA:BB:CC-type-silent-host", nil, nil},
+		{"uni/tn-X/ap-A/epg-B/stcep-00:50:56:AA:BB:CC-type-silent-host", "", "uni/tn-X/ap-A/epg-B/stcep-00:50:56:AA:BB:CC-type-silent-host", nil, nil},
+		{"fvCEp:uni/tn-X/ap-A/epg-B/cep-00:50:56:aa:bb:cc", "fvCEp", "uni/tn-X/ap-A/epg-B/cep-00:50:56:aa:bb:cc", nil, nil},
+		{"fvCEp:uni/tn-X/ap-A/epg-B/cep-00:50:56:AA:BB:CC:fvIp:mac", "fvCEp", "uni/tn-X/ap-A/epg-B/cep-00:50:56:AA:BB:CC", []string{"fvIp"}, []string{"mac"}},
+		{"fvSubnet:uni/tn-X/BD-Y/subnet-[2001:db8::1/64]::ip,scope", "fvSubnet", "uni/tn-X/BD-Y/subnet-[2001:db8::1/64]", nil, []string{"ip", "scope"}},
+		{"l3extOut:uni/tn-X/out-Y:l3extInstP", "l3extOut", "uni/tn-X/out-Y", []string{"l3extInstP"}, nil},
+	}
+	for _, c := range cases {
+		className, dn, childClasses, contentKeys, err := parseImportId(c.id)
+		if err != nil {
+			t.Fatalf("parseImportId(%s): %s", c.id, err)
+		}
+		if className != c.className || dn != c.dn || !reflect.DeepEqual(childClasses, c.childClasses) || !reflect.DeepEqual(contentKeys, c.contentKeys) {
+			t.Fatalf("parseImportId(%s): got %q, %q, %v, %v", c.id, className, dn, childClasses, contentKeys)
+		}
+		if c.className == "" {
+			continue
+		}
+		// IDs rendered by generate are parsed back
+		id := importId(c.className, c.dn, c.childClasses, c.contentKeys)
+		if className, dn, _, _, err := parseImportId(id); err != nil || className != c.className || dn != c.dn {
+			t.Fatalf("parseImportId(%s): got %q, %q, %v", id, className, dn, err)
+		}
+	}
+
+	for _, id := range []string{"", "fvTenant:", "uni/tn-[X", "fvTenant:uni//tn-X"} {
+		if _, _, _, _, err := parseImportId(id); err == nil {
+			t.Fatalf("parseImportId(%s): expected error", id)
+		}
+	}
+}
+
+func TestResourceAciRestDryRun(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "requests.jsonl")
+	meta := apiClient{DryRunPath: path}
+	config := `{"dn": "uni/tn-EXAMPLE", "class_name": "fvTenant", "content": {"name": "EXAMPLE", "descr": "%s"}}`
+
+	// Creating a parent and its child writes both requests, as the parent does not fail the apply
+	newState, diags := testAciRestApply(t, fmt.Sprintf(config, "Description"), nil, meta)
+	if diags.HasError() || len(diags) != 1 || !strings.Contains(diags[0].Summary, "dry-run") {
+		t.Fatalf("expected dry-run warning for create, got %v", diags)
+	}
+	if newState == nil || newState.ID != "uni/tn-EXAMPLE" {
+		t.Fatalf("expected planned object in state, got %v", newState)
+	}
+	childConfig := `{"dn": "uni/tn-EXAMPLE/ctx-VRF1", "class_name": "fvCtx", "content": {"name": "VRF1"}}`
+	if childState, diags := testAciRestApply(t, childConfig, nil, meta); diags.HasError() || childState == nil || childState.ID != "uni/tn-EXAMPLE/ctx-VRF1" {
+		t.Fatalf("expected dry-run warning for create of child, got %v", diags)
+	}
+
+	state := map[string]string{
+		"id":              "uni/tn-EXAMPLE",
+		"dn":              "uni/tn-EXAMPLE",
+		"class_name":      "fvTenant",
+		"content.%":       "2",
+		"content.name":    "EXAMPLE",
+		"content.descr":   "Description",
+		"attributes.%":    "1",
+		"attributes.name": "EXAMPLE",
+	}
+	newState, diags = testAciRestApply(t, fmt.Sprintf(config, "Updated"), state, meta)
+	if diags.HasError() || len(diags) != 1 {
+		t.Fatalf("expected dry-run warning for update, got %v", diags)
+	}
+	if newState == nil || newState.Attributes["content.descr"] != "Description" {
+		t.Fatalf("expected prior state to be kept, got %v", newState)
+	}
+
+	newState, diags = resourceAciRest().Apply(context.Background(), &terraform.InstanceState{ID: state["id"], Attributes: state}, &terraform.InstanceDiff{Destroy: true}, meta)
+	if !diags.HasError() {
+		t.Fatalf("expected dry-run error for delete")
+	}
+	if newState == nil || newState.ID != "uni/tn-EXAMPLE" {
+		t.Fatalf("expected object to be kept in state, got %v", newState)
+	}
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != 4 || !strings.Contains(lines[1], `"path":"/api/mo/uni/tn-EXAMPLE/ctx-VRF1.json"`) || !strings.Contains(lines[2], `"descr":"Updated"`) || !strings.HasPrefix(lines[3], `{"method":"DELETE"`) {
+		t.Fatalf("unexpected requests: %s", data)
+	}
+}
+
+func testAccAciRestConfig_dryRun(path string, name string) string {
+	return fmt.Sprintf(`
+	provider "aci" {
+		dry_run_path = "%[1]s"
+	}
+
+	resource "aci_rest" "fvTenant" {
+		dn = "uni/tn-%[2]s"
+		class_name = "fvTenant"
+		content = {
+			name = "%[2]s"
+		}
+	}
+
+	resource "aci_rest" "fvCtx" {
+		dn = "${aci_rest.fvTenant.id}/ctx-%[2]s"
+		class_name = "fvCtx"
+		content = {
+			name = "%[2]s"
+		}
+	}
+
+	resource "aci_rest" "fvBD" {
+		dn = "${aci_rest.fvTenant.id}/BD-%[2]s"
+		class_name = "fvBD"
+		content = {
+			name = "%[2]s"
+		}
+
+		child {
+			rn         = "rsctx"
+			class_name = "fvRsCtx"
+			content = {
+				tnFvCtxName = aci_rest.fvCtx.content.name
+			}
+		}
+	}
+	`, path, name)
+}
+
+func testAccAciRestConfig_readOnly() string {
+	return `
+	provider "aci" {
+		read_only = true
+	}
+	`
+}
+
 func testAccAciRestConfig_tenant(name string, description string) string {
 	return fmt.Sprintf(`
 	resource "aci_rest" "fvTenant" {
@@ -166,6 +771,76 @@ func testAccAciRestConfig_tenantVrf(name string) string {
 	`, name)
 }
 
+func testAccAciRestConfig_tenantChildren(name string, description string) string {
+	return fmt.Sprintf(`
+	resource "aci_rest" "fvTenant" {
+		dn = "uni/tn-%[1]s"
+		class_name = "fvTenant"
+		content = {
+			name = "%[1]s"
+		}
+
+		children = {
+			"ctx-%[1]s" = jsonencode({
+				class_name = "fvCtx"
+				content = {
+					name  = "%[1]s"
+					descr = "%[2]s"
+				}
+			})
+		}
+	}
+	`, name, description)
+}
+
+func testAccAciRestConfig_sensitiveContent(name string, password string) string {
+	return fmt.Sprintf(`
+	resource "aci_rest" "aaaUser" {
+		dn = "uni/userext/user-%[1]s"
+		class_name = "aaaUser"
+		content = {
+			name = "%[1]s"
+		}
+		sensitive_content = {
+			pwd = "%[2]s"
+		}
+	}
+	`, name, password)
+}
+
+func testAccAciRestConfig_faultCheck(name string) string {
+	return fmt.Sprintf(`
+	resource "aci_rest" "fvTenant" {
+		dn = "uni/tn-%[1]s"
+		class_name = "fvTenant"
+		content = {
+			name = "%[1]s"
+		}
+		fault_check {
+			timeout = 4
+		}
+	}
+	`, name)
+}
+
+func testAccAciRestConfig_waitFor(name string) string {
+	return fmt.Sprintf(`
+	resource "aci_rest" "fvTenant" {
+		dn = "uni/tn-%[1]s"
+		class_name = "fvTenant"
+		content = {
+			name = "%[1]s"
+		}
+		wait_for {
+			attributes = {
+				name = "%[1]s"
+			}
+			timeout = 30
+		}
+	}
+	`, name)
+}
+
 func testAccCheckAciRestObject(name string) resource.TestCheckFunc {
 	return func(s *terraform.State) error {
 		rs, ok := s.RootModule().Resources[name]
@@ -178,7 +853,7 @@ func testAccCheckAciRestObject(name string) resource.TestCheckFunc {
 			return fmt.Errorf("No aci_rest dn attribute was set")
 		}
 
-		client := testAccProvider.Meta().(apiClient).Client
+		client := testAccProvider.Meta().(apiClient).Client()
 
 		cont, err := client.Get(rs.Primary.ID)
 		if err != nil {
@@ -206,8 +881,36 @@ func testAccCheckAciRestObject(name string) resource.TestCheckFunc {
 	}
 }
 
+// testAccCheckSensitiveHash checks the hash of a sensitive value with the salt of the resource.
+func testAccCheckSensitiveHash(name string, attr string, value string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources[name]
+		if !ok {
+			return fmt.Errorf("Not found: %s", name)
+		}
+		hash := hashSensitive(rs.Primary.Attributes["sensitive_salt"], value)
+		return resource.TestCheckResourceAttr(name, "sensitive_content."+attr, hash)(s)
+	}
+}
+
+// testAccCheckDryRunRequests checks that a POST request of each object was written to the dry-run file.
+func testAccCheckDryRunRequests(path string, dns ...string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		for _, dn := range dns {
+			if !strings.Contains(string(data), `{"method":"POST","path":"`+dnUrlPath(dn)+`"`) {
+				return fmt.Errorf("Request of %s not written to dry-run file: %s", dn, data)
+			}
+		}
+		return nil
+	}
+}
+
 func testAccCheckAciRestDestroy(s *terraform.State) error {
-	client := testAccProvider.Meta().(apiClient).Client
+	client := testAccProvider.Meta().(apiClient).Client()
 
 	for _, rs := range s.RootModule().Resources {
 
@@ -226,7 +929,7 @@ func testAccCheckAciRestDestroy(s *terraform.State) error {
 }
 
 func testAccCheckAciRestStillExists(s *terraform.State) error {
-	client := testAccProvider.Meta().(apiClient).Client
+	client := testAccProvider.Meta().(apiClient).Client()
 
 	for _, rs := range s.RootModule().Resources {
 
@@ -1,9 +1,12 @@
 package provider
 
+// toStrMap converts a map of a schema or response to a map of strings, values which are not strings are ignored.
 func toStrMap(inputMap map[string]interface{}) map[string]string {
 	rt := make(map[string]string)
 	for key, value := range inputMap {
-		rt[key] = value.(string)
+		if v, ok := value.(string); ok {
+			rt[key] = v
+		}
 	}
 
 	return rt
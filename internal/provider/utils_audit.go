@@ -0,0 +1,94 @@
+package provider
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/ciscoecosystem/aci-go-client/container"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// auditRecord is a request which modified the configuration of the APIC, written to the audit log.
+type auditRecord struct {
+	Timestamp string          `json:"timestamp"`
+	Username  string          `json:"username"`
+	Method    string          `json:"method"`
+	Dn        string          `json:"dn"`
+	ClassName string          `json:"class_name"`
+	Payload   json.RawMessage `json:"payload,omitempty"`
+	Status    int             `json:"status"`
+	ErrorCode string          `json:"error_code,omitempty"`
+	Error     string          `json:"error,omitempty"`
+	Retries   int             `json:"retries"`
+}
+
+// checkAuditLog makes sure the audit log can be written before any changes are made.
+func checkAuditLog(path string) error {
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("Failed to open audit log %s: %s", path, err)
+	}
+	return f.Close()
+}
+
+// writeAuditRecord appends a record of a POST or DELETE request and its response to the audit
+// log. Sensitive values of the payload are replaced by their hashes as stored in state.
+func writeAuditRecord(path string, d *schema.ResourceData, meta interface{}, method string, respCont *container.Container, status int, diags diag.Diagnostics, retries int) error {
+	record := auditRecord{
+		Timestamp: time.Now().UTC().Format(time.RFC3339Nano),
+		Username:  meta.(apiClient).Username,
+		Method:    method,
+		Dn:        d.Get("dn").(string),
+		ClassName: d.Get("class_name").(string),
+		Status:    status,
+		Retries:   retries,
+	}
+	if method == "POST" {
+		cont, payloadDiags := restPayload(d, meta, true)
+		if payloadDiags.HasError() {
+			return fmt.Errorf("Failed to prepare audit log payload: %s", payloadDiags[0].Summary)
+		}
+		record.Payload = json.RawMessage(cont.String())
+	}
+	if respCont != nil {
+		if resp, respDiags := decodeResponse(respCont); !respDiags.HasError() && resp.Error != nil {
+			record.ErrorCode = resp.Error.Code
+		}
+	}
+	if diags.HasError() {
+		record.Error = diags[0].Summary
+	}
+	if err := appendJsonLine(path, record); err != nil {
+		return fmt.Errorf("Failed to write audit log: %s", err)
+	}
+	return nil
+}
+
+// auditLogFailure is the detail of the error for a request which could not be written to the audit log.
+const auditLogFailure = "The request was sent to the APIC, but could not be written to the audit log."
+
+// auditLogDiags returns the error for a request which could not be written to the audit log.
+func auditLogDiags(err error) diag.Diagnostics {
+	return diag.Diagnostics{{
+		Severity: diag.Error,
+		Summary:  err.Error(),
+		Detail:   auditLogFailure,
+	}}
+}
+
+// splitAuditLogDiags separates the audit log errors from the diagnostics of a request, so that a
+// request is not sent to the APIC again only because it could not be written to the audit log.
+func splitAuditLogDiags(diags diag.Diagnostics) (diag.Diagnostics, diag.Diagnostics) {
+	var requestDiags, auditDiags diag.Diagnostics
+	for _, d := range diags {
+		if d.Detail == auditLogFailure {
+			auditDiags = append(auditDiags, d)
+		} else {
+			requestDiags = append(requestDiags, d)
+		}
+	}
+	return requestDiags, auditDiags
+}
@@ -0,0 +1,83 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+func TestApicRestAuditLog(t *testing.T) {
+	srv := testApicServer()
+	defer srv.Close()
+
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	if err := checkAuditLog(path); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	cl := apiClient{URL: srv.URL, Username: "admin", Password: "password", AuditLogPath: path}
+	cl.Controllers = newControllers(cl)
+	d := schema.TestResourceDataRaw(t, resourceAciRest().Schema, map[string]interface{}{
+		"dn":         "uni/tn-EXAMPLE",
+		"class_name": "fvTenant",
+	})
+	if _, diags := ApicRest(context.Background(), d, cl, "DELETE", false, 2); diags.HasError() {
+		t.Fatalf("unexpected error: %s", diags[0].Summary)
+	}
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	var record auditRecord
+	if err := json.Unmarshal(data, &record); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if record.Method != "DELETE" || record.Dn != "uni/tn-EXAMPLE" || record.ClassName != "fvTenant" || record.Username != "admin" {
+		t.Fatalf("unexpected record: %s", data)
+	}
+	if record.Status != 200 || record.Retries != 2 || record.Timestamp == "" || record.Payload != nil {
+		t.Fatalf("unexpected record: %s", data)
+	}
+
+	if err := checkAuditLog(filepath.Join(t.TempDir(), "missing", "audit.jsonl")); err == nil {
+		t.Fatalf("expected error for invalid audit log path")
+	}
+}
+
+func TestApicRestAuditLogFailure(t *testing.T) {
+	deletes := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "DELETE" {
+			deletes++
+		}
+		if r.URL.Path == "/api/aaaLogin.json" {
+			w.Write([]byte(`{"totalCount": "1", "imdata": [{"aaaLogin": {"attributes": {"token": "TOKEN", "creationTime": "1600000000", "refreshTimeoutSeconds": "600"}}}]}`))
+			return
+		}
+		w.Write([]byte(`{"totalCount": "0", "imdata": []}`))
+	}))
+	defer srv.Close()
+
+	// A directory can not be opened for writing
+	cl := apiClient{URL: srv.URL, Username: "admin", Password: "password", AuditLogPath: t.TempDir(), Retries: 3}
+	cl.Controllers = newControllers(cl)
+	state := &terraform.InstanceState{ID: "uni/tn-EXAMPLE", Attributes: map[string]string{"id": "uni/tn-EXAMPLE", "dn": "uni/tn-EXAMPLE", "class_name": "fvTenant"}}
+	newState, diags := resourceAciRest().Apply(context.Background(), state, &terraform.InstanceDiff{Destroy: true}, cl)
+	if !diags.HasError() || !strings.Contains(diags[0].Summary, "audit log") {
+		t.Fatalf("expected audit log error, got %v", diags)
+	}
+	if deletes != 1 {
+		t.Fatalf("expected request to be sent once, got %d", deletes)
+	}
+	if newState != nil && newState.ID != "" {
+		t.Fatalf("expected deleted object to be removed from state, got %s", newState.ID)
+	}
+}
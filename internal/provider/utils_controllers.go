@@ -0,0 +1,91 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/ciscoecosystem/aci-go-client/client"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// controllers holds a client with its own session for each APIC of a cluster and the index of
+// the client currently in use, which changes when a controller becomes unreachable.
+type controllers struct {
+	mutex   sync.Mutex
+	urls    []string
+	clients []*client.Client
+	active  int
+}
+
+func newControllers(c apiClient) *controllers {
+	ctrls := &controllers{urls: c.controllerUrls()}
+	for _, url := range ctrls.urls {
+		ctrls.clients = append(ctrls.clients, c.newClient(url))
+	}
+	return ctrls
+}
+
+// current returns the index and client of the active controller.
+func (c *controllers) current() (int, *client.Client) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.active, c.clients[c.active]
+}
+
+func (c *controllers) count() int {
+	return len(c.clients)
+}
+
+// failover switches to the next controller and returns its URL, unless another request already
+// switched away from the failed controller.
+func (c *controllers) failover(index int) string {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.active != index {
+		return ""
+	}
+	c.active = (index + 1) % len(c.clients)
+	return c.urls[c.active]
+}
+
+// healthCheck makes the first reachable controller the active one. The login domains are
+// queried as this does not require authentication.
+func (c *controllers) healthCheck(ctx context.Context) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	failures := make([]string, 0)
+	for i, cl := range c.clients {
+		req, err := cl.MakeRestRequest("GET", "/api/aaaListDomains.json", nil, false)
+		if err == nil {
+			checkCtx, cancel := context.WithTimeout(ctx, HealthCheckTimeout)
+			_, _, err = cl.Do(req.WithContext(checkCtx))
+			cancel()
+		}
+		if err == nil || !isConnectionError(ctx, err) {
+			c.active = i
+			return nil
+		}
+		tflog.SubsystemWarn(ctx, logHttp, "Controller unreachable", map[string]interface{}{"controller": c.urls[i], "error": err.Error()})
+		failures = append(failures, fmt.Sprintf("%s: %s", c.urls[i], err))
+	}
+	return fmt.Errorf("No controller reachable: %s", strings.Join(failures, ", "))
+}
+
+// isConnectionError returns true if a request failed because the controller could not be reached,
+// i.e. the connection was refused or timed out, but not if the request was cancelled by Terraform.
+// Other errors, e.g. of certificate verification, are not resolved by failing over.
+func isConnectionError(ctx context.Context, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	var opErr *net.OpError
+	if errors.As(err, &opErr) && opErr.Op == "dial" {
+		return true
+	}
+	return errors.Is(err, os.ErrDeadlineExceeded) || errors.Is(err, context.DeadlineExceeded)
+}
@@ -0,0 +1,81 @@
+package provider
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func testApicServer() *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/aaaLogin.json":
+			w.Write([]byte(`{"totalCount": "1", "imdata": [{"aaaLogin": {"attributes": {"token": "TOKEN", "creationTime": "1600000000", "refreshTimeoutSeconds": "600"}}}]}`))
+		case "/api/mo/uni/tn-EXAMPLE.json":
+			w.Write([]byte(`{"totalCount": "1", "imdata": [{"fvTenant": {"attributes": {"dn": "uni/tn-EXAMPLE", "name": "EXAMPLE"}}}]}`))
+		default:
+			w.Write([]byte(`{"totalCount": "0", "imdata": []}`))
+		}
+	}))
+}
+
+func TestControllersFailover(t *testing.T) {
+	down := httptest.NewServer(http.NotFoundHandler())
+	down.Close()
+	up := testApicServer()
+	defer up.Close()
+
+	cl := apiClient{URL: down.URL, URLs: []string{up.URL, down.URL}, Username: "admin", Password: "password"}
+	if urls := cl.controllerUrls(); len(urls) != 2 {
+		t.Fatalf("expected duplicate URLs to be removed, got %v", urls)
+	}
+	cl.Controllers = newControllers(cl)
+
+	if err := cl.Controllers.healthCheck(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if index, _ := cl.Controllers.current(); index != 1 {
+		t.Fatalf("expected reachable controller to be active, got %d", index)
+	}
+
+	cl.Controllers.active = 0
+	cont, diags := apicRestRequest(context.Background(), cl, "GET", "/api/mo/uni/tn-EXAMPLE.json", nil)
+	if diags.HasError() {
+		t.Fatalf("unexpected error: %s", diags[0].Summary)
+	}
+	if obj, diags := decodeSingleObject(cont, "fvTenant"); diags.HasError() || obj.Attributes["name"] != "EXAMPLE" {
+		t.Fatalf("unexpected response: %v", cont)
+	}
+	if index, _ := cl.Controllers.current(); index != 1 {
+		t.Fatalf("expected failover to reachable controller, got %d", index)
+	}
+
+	cl = apiClient{URL: down.URL, Username: "admin", Password: "password"}
+	cl.Controllers = newControllers(cl)
+	if err := cl.Controllers.healthCheck(context.Background()); err == nil {
+		t.Fatalf("expected error without reachable controller")
+	}
+}
+
+func TestIsConnectionError(t *testing.T) {
+	down := httptest.NewServer(http.NotFoundHandler())
+	down.Close()
+	_, err := http.Get(down.URL)
+	if !isConnectionError(context.Background(), err) {
+		t.Fatalf("expected connection error, got %s", err)
+	}
+
+	up := httptest.NewTLSServer(http.NotFoundHandler())
+	defer up.Close()
+	_, err = http.Get(up.URL)
+	if err == nil || isConnectionError(context.Background(), err) {
+		t.Fatalf("expected certificate verification error not to be a connection error, got %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err = http.Get(down.URL); isConnectionError(ctx, err) {
+		t.Fatalf("expected cancelled request not to be a connection error")
+	}
+}
@@ -0,0 +1,87 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os/exec"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// commandCredentials are the credentials returned by a credential command as a JSON object.
+type commandCredentials struct {
+	Username             string `json:"username"`
+	Password             string `json:"password"`
+	PrivateKey           string `json:"private_key"`
+	PrivateKeyPassphrase string `json:"private_key_passphrase"`
+	CertName             string `json:"cert_name"`
+}
+
+// readPasswordFile reads the password from a file, ignoring a trailing newline.
+func readPasswordFile(path string) (string, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("Failed to read password file %s: %s", path, err)
+	}
+	password := strings.TrimRight(string(data), "\r\n")
+	if password == "" {
+		return "", fmt.Errorf("Password file %s is empty", path)
+	}
+	return password, nil
+}
+
+// runCredentialCommand executes the credential command and decodes the credentials written to stdout.
+// Errors written to stderr are included in the error, the output is not as it contains secrets.
+func runCredentialCommand(ctx context.Context, command []string) (*commandCredentials, error) {
+	if len(command) == 0 || command[0] == "" {
+		return nil, fmt.Errorf("Credential command must not be empty")
+	}
+	ctx, cancel := context.WithTimeout(ctx, CredentialCommandTimeout)
+	defer cancel()
+	tflog.Debug(ctx, "Running credential command", map[string]interface{}{"command": command[0]})
+	cmd := exec.CommandContext(ctx, command[0], command[1:]...)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("Credential command %s failed: %s: %s", command[0], err, strings.TrimSpace(stderr.String()))
+	}
+	var creds commandCredentials
+	if err := json.Unmarshal(stdout.Bytes(), &creds); err != nil {
+		return nil, fmt.Errorf("Credential command %s returned invalid JSON: %s", command[0], err)
+	}
+	if creds.Password == "" && (creds.PrivateKey == "" || creds.CertName == "") {
+		return nil, fmt.Errorf("Credential command %s must return either 'password' or 'private_key' and 'cert_name'", command[0])
+	}
+	return &creds, nil
+}
+
+// applyCredentials loads the credentials from the password file and the credential command, where
+// the latter takes precedence over any other configured credentials.
+func (c *apiClient) applyCredentials(ctx context.Context, passwordFile string, command []string) error {
+	if passwordFile != "" {
+		password, err := readPasswordFile(passwordFile)
+		if err != nil {
+			return err
+		}
+		c.Password = password
+	}
+	if len(command) > 0 {
+		creds, err := runCredentialCommand(ctx, command)
+		if err != nil {
+			return err
+		}
+		if creds.Username != "" {
+			c.Username = creds.Username
+		}
+		c.Password = creds.Password
+		c.PrivateKey = creds.PrivateKey
+		c.PrivateKeyPassphrase = creds.PrivateKeyPassphrase
+		c.Certname = creds.CertName
+	}
+	return nil
+}
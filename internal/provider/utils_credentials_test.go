@@ -0,0 +1,48 @@
+package provider
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestApplyCredentials(t *testing.T) {
+	passwordFile := filepath.Join(t.TempDir(), "password")
+	if err := ioutil.WriteFile(passwordFile, []byte("secret\n"), 0600); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	cl := apiClient{Username: "admin", Password: "password"}
+	if err := cl.applyCredentials(context.Background(), passwordFile, nil); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if cl.Password != "secret" {
+		t.Fatalf("unexpected password: %s", cl.Password)
+	}
+	if err := cl.applyCredentials(context.Background(), filepath.Join(t.TempDir(), "missing"), nil); err == nil {
+		t.Fatalf("expected error for missing password file")
+	}
+
+	if _, err := os.Stat("/bin/sh"); err != nil {
+		t.Skip("no shell available")
+	}
+	command := []string{"/bin/sh", "-c", `echo '{"username": "terraform", "private_key": "admin.key", "cert_name": "admin"}'`}
+	if err := cl.applyCredentials(context.Background(), passwordFile, command); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if cl.Username != "terraform" || cl.Password != "" || cl.PrivateKey != "admin.key" || cl.Certname != "admin" {
+		t.Fatalf("unexpected credentials: %s %s %s", cl.Username, cl.PrivateKey, cl.Certname)
+	}
+
+	cases := [][]string{
+		{"/bin/sh", "-c", "echo invalid"},
+		{"/bin/sh", "-c", `echo '{"username": "terraform"}'`},
+		{"/bin/sh", "-c", "echo failed >&2; exit 1"},
+	}
+	for _, command := range cases {
+		if err := cl.applyCredentials(context.Background(), "", command); err == nil {
+			t.Fatalf("expected error for command %v", command)
+		}
+	}
+}
@@ -0,0 +1,150 @@
+package provider
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/ciscoecosystem/aci-go-client/container"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+)
+
+// restResponse is a decoded APIC response.
+type restResponse struct {
+	TotalCount int
+	Objects    []restObject
+	Error      *restError
+}
+
+// restError is an error returned by the APIC within 'imdata'.
+type restError struct {
+	Code string
+	Text string
+}
+
+// restObject is a decoded managed object, attributes which are not strings are ignored.
+type restObject struct {
+	ClassName  string
+	Attributes map[string]string
+	Children   []restObject
+}
+
+// decodeResponse decodes the 'imdata' and 'totalCount' of an APIC response.
+func decodeResponse(cont *container.Container) (*restResponse, diag.Diagnostics) {
+	if cont == nil {
+		return nil, diag.Errorf("Failed to decode response: empty response")
+	}
+	data, ok := cont.Data().(map[string]interface{})
+	if !ok {
+		return nil, diag.Errorf("Failed to decode response: expected a JSON object")
+	}
+	resp := &restResponse{}
+	if totalCount, ok := data["totalCount"].(string); ok {
+		count, err := strconv.Atoi(totalCount)
+		if err != nil {
+			return nil, diag.Errorf("Failed to decode response: invalid totalCount %q", totalCount)
+		}
+		resp.TotalCount = count
+	}
+	imdata, ok := data["imdata"].([]interface{})
+	if !ok {
+		return nil, diag.Errorf("Failed to decode response: missing imdata")
+	}
+	for _, item := range imdata {
+		itemMap, ok := item.(map[string]interface{})
+		if !ok {
+			return nil, diag.Errorf("Failed to decode response: unexpected imdata element")
+		}
+		for className, value := range itemMap {
+			obj, err := decodeObject(className, value)
+			if err != nil {
+				return nil, diag.Errorf("Failed to decode response: %s", err)
+			}
+			if className == "error" {
+				resp.Error = &restError{Code: obj.Attributes["code"], Text: obj.Attributes["text"]}
+				continue
+			}
+			resp.Objects = append(resp.Objects, obj)
+		}
+	}
+	return resp, nil
+}
+
+// decodeObject decodes a single managed object, e.g. the value of the 'fvTenant' key of an imdata element.
+func decodeObject(className string, value interface{}) (restObject, error) {
+	obj := restObject{ClassName: className, Attributes: make(map[string]string)}
+	valueMap, ok := value.(map[string]interface{})
+	if !ok {
+		return obj, fmt.Errorf("object of class %s is not a JSON object", className)
+	}
+	if attributes, ok := valueMap["attributes"]; ok {
+		attrMap, ok := attributes.(map[string]interface{})
+		if !ok {
+			return obj, fmt.Errorf("attributes of class %s are not a JSON object", className)
+		}
+		for attr, v := range attrMap {
+			if s, ok := v.(string); ok {
+				obj.Attributes[attr] = s
+			}
+		}
+	}
+	if children, ok := valueMap["children"]; ok {
+		childList, ok := children.([]interface{})
+		if !ok {
+			return obj, fmt.Errorf("children of class %s are not a JSON array", className)
+		}
+		for _, child := range childList {
+			childMap, ok := child.(map[string]interface{})
+			if !ok {
+				return obj, fmt.Errorf("child of class %s is not a JSON object", className)
+			}
+			for childClassName, childValue := range childMap {
+				childObj, err := decodeObject(childClassName, childValue)
+				if err != nil {
+					return obj, err
+				}
+				obj.Children = append(obj.Children, childObj)
+			}
+		}
+	}
+	return obj, nil
+}
+
+// decodeSingleObject decodes a response which is expected to contain a single object of the given class.
+// An empty class name accepts objects of any class.
+func decodeSingleObject(cont *container.Container, className string) (*restObject, diag.Diagnostics) {
+	resp, diags := decodeResponse(cont)
+	if diags.HasError() {
+		return nil, diags
+	}
+	if resp.Error != nil {
+		return nil, diag.Errorf("APIC error %s: %s", resp.Error.Code, resp.Error.Text)
+	}
+	if len(resp.Objects) == 0 {
+		return nil, diag.Errorf("Response does not contain an object")
+	}
+	obj := resp.Objects[0]
+	if className != "" && obj.ClassName != className {
+		return nil, diag.Errorf("Failed to retrieve REST payload for class: %s, got: %s.", className, obj.ClassName)
+	}
+	return &obj, nil
+}
+
+// rn returns the relative name of a child object, which is either returned as attribute or derived from its dn.
+func (o restObject) rn() string {
+	if rn := o.Attributes["rn"]; rn != "" {
+		return rn
+	}
+	if dn := o.Attributes["dn"]; dn != "" {
+		return dnRn(dn)
+	}
+	return ""
+}
+
+// childIndex maps the relative names of the children of an object to the children.
+func (o restObject) childIndex() map[string]restObject {
+	index := make(map[string]restObject, len(o.Children))
+	for _, child := range o.Children {
+		index[child.rn()] = child
+	}
+	return index
+}
@@ -0,0 +1,50 @@
+package provider
+
+import (
+	"testing"
+
+	"github.com/ciscoecosystem/aci-go-client/container"
+)
+
+func TestDecodeResponse(t *testing.T) {
+	cont, err := container.ParseJSON([]byte(`{"totalCount": "1", "imdata": [{"fvTenant": {"attributes": {"dn": "uni/tn-EXAMPLE", "name": "EXAMPLE"}, "children": [
+		{"fvCtx": {"attributes": {"rn": "ctx-VRF1", "name": "VRF1"}}},
+		{"fvBD": {"attributes": {"dn": "uni/tn-EXAMPLE/BD-BD1", "name": "BD1", "count": 1}}}
+	]}}]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	obj, diags := decodeSingleObject(cont, "fvTenant")
+	if diags.HasError() {
+		t.Fatalf("unexpected error: %s", diags[0].Summary)
+	}
+	if obj.Attributes["name"] != "EXAMPLE" || len(obj.Children) != 2 {
+		t.Fatalf("unexpected object: %v", obj)
+	}
+	index := obj.childIndex()
+	if index["ctx-VRF1"].ClassName != "fvCtx" || index["ctx-VRF1"].Attributes["name"] != "VRF1" {
+		t.Fatalf("unexpected child ctx-VRF1: %v", index["ctx-VRF1"])
+	}
+	if _, ok := index["BD-BD1"].Attributes["count"]; ok || index["BD-BD1"].Attributes["name"] != "BD1" {
+		t.Fatalf("unexpected child BD-BD1: %v", index["BD-BD1"])
+	}
+	if _, diags := decodeSingleObject(cont, "fvAp"); !diags.HasError() {
+		t.Fatalf("expected error for unexpected class")
+	}
+
+	cont, _ = container.ParseJSON([]byte(`{"totalCount": "1", "imdata": [{"error": {"attributes": {"code": "107", "text": "Cannot delete object"}}}]}`))
+	resp, diags := decodeResponse(cont)
+	if diags.HasError() {
+		t.Fatalf("unexpected error: %s", diags[0].Summary)
+	}
+	if resp.TotalCount != 1 || resp.Error == nil || resp.Error.Code != "107" || len(resp.Objects) != 0 {
+		t.Fatalf("unexpected response: %v", resp)
+	}
+
+	for _, data := range []string{`{"imdata": {}}`, `{"imdata": ["x"]}`, `{"imdata": [{"fvTenant": {"attributes": []}}]}`, `{"imdata": [{"fvTenant": {"children": {}}}]}`, `[]`} {
+		cont, _ = container.ParseJSON([]byte(data))
+		if _, diags := decodeResponse(cont); !diags.HasError() {
+			t.Fatalf("expected error for %s", data)
+		}
+	}
+}
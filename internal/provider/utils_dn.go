@@ -0,0 +1,107 @@
+package provider
+
+import (
+	"fmt"
+	"strings"
+)
+
+// splitOutsideBrackets splits s at each separator which is not enclosed in square brackets,
+// e.g. the colons of an IPv6 address within a relative name.
+func splitOutsideBrackets(s string, sep byte) []string {
+	var parts []string
+	depth := 0
+	start := 0
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '[':
+			depth++
+		case ']':
+			if depth > 0 {
+				depth--
+			}
+		case sep:
+			if depth == 0 {
+				parts = append(parts, s[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return append(parts, s[start:])
+}
+
+// parseDn splits a distinguished name into its relative names. Relative names may contain
+// slashes within square brackets, e.g. 'rspathAtt-[topology/pod-1/paths-101/pathep-[eth1/1]]'.
+func parseDn(dn string) ([]string, error) {
+	depth := 0
+	for _, c := range dn {
+		if c == '[' {
+			depth++
+		} else if c == ']' {
+			depth--
+		}
+		if depth < 0 {
+			break
+		}
+	}
+	if depth != 0 {
+		return nil, fmt.Errorf("Unbalanced brackets in distinguished name: %s", dn)
+	}
+	rns := splitOutsideBrackets(dn, '/')
+	for _, rn := range rns {
+		if rn == "" {
+			return nil, fmt.Errorf("Empty relative name in distinguished name: %s", dn)
+		}
+	}
+	return rns, nil
+}
+
+// dnRn returns the relative name of an object.
+func dnRn(dn string) string {
+	rns := splitOutsideBrackets(dn, '/')
+	return rns[len(rns)-1]
+}
+
+// dnParent returns the distinguished name of the parent object or an empty string for top-level objects.
+func dnParent(dn string) string {
+	rns := splitOutsideBrackets(dn, '/')
+	return strings.Join(rns[:len(rns)-1], "/")
+}
+
+// dnUrlPath returns the REST API path of an object.
+func dnUrlPath(dn string) string {
+	return "/api/mo/" + escapeDn(dn) + ".json"
+}
+
+// escapeDn percent-encodes all characters of a distinguished name which are not valid within
+// a URL path. Slashes, brackets and colons are kept as the APIC expects them unencoded.
+func escapeDn(dn string) string {
+	var b strings.Builder
+	for i := 0; i < len(dn); i++ {
+		c := dn[i]
+		if shouldEscapeDnChar(c) {
+			fmt.Fprintf(&b, "%%%02X", c)
+		} else {
+			b.WriteByte(c)
+		}
+	}
+	return b.String()
+}
+
+func shouldEscapeDnChar(c byte) bool {
+	if 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9' {
+		return false
+	}
+	switch c {
+	case '-', '_', '.', '~', '/', '[', ']', ':', '@', '!', '$', '&', '\'', '(', ')', '*', '+', ',', ';', '=':
+		return false
+	}
+	return true
+}
+
+// validateDn is a schema validation function for distinguished names.
+func validateDn(val interface{}, key string) (warns []string, errs []error) {
+	if _, err := parseDn(val.(string)); err != nil {
+		errs = append(errs, fmt.Errorf("%q is not a valid distinguished name: %s", key, err))
+	}
+	return
+}
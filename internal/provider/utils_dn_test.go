@@ -0,0 +1,53 @@
+package provider
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseDn(t *testing.T) {
+	cases := []struct {
+		dn  string
+		rns []string
+	}{
+		{"uni/tn-EXAMPLE", []string{"uni", "tn-EXAMPLE"}},
+		{"uni/tn-X/out-Y/instP-Z/extsubnet-[10.0.0.0/8]", []string{"uni", "tn-X", "out-Y", "instP-Z", "extsubnet-[10.0.0.0/8]"}},
+		{"uni/tn-X/ap-A/epg-B/rspathAtt-[topology/pod-1/paths-101/pathep-[eth1/1]]", []string{"uni", "tn-X", "ap-A", "epg-B", "rspathAtt-[topology/pod-1/paths-101/pathep-[eth1/1]]"}},
+		{"uni/tn-X/BD-Y/subnet-[2001:db8::1/64]", []string{"uni", "tn-X", "BD-Y", "subnet-[2001:db8::1/64]"}},
+	}
+	for _, c := range cases {
+		rns, err := parseDn(c.dn)
+		if err != nil {
+			t.Fatalf("parseDn(%s): %s", c.dn, err)
+		}
+		if !reflect.DeepEqual(rns, c.rns) {
+			t.Fatalf("parseDn(%s): expected %v, got %v", c.dn, c.rns, rns)
+		}
+		if rn := dnRn(c.dn); rn != c.rns[len(c.rns)-1] {
+			t.Fatalf("dnRn(%s): got %s", c.dn, rn)
+		}
+	}
+
+	for _, dn := range []string{"uni/tn-[X", "uni/tn-X]", "uni//tn-X", ""} {
+		if _, err := parseDn(dn); err == nil {
+			t.Fatalf("parseDn(%s): expected error", dn)
+		}
+	}
+}
+
+func TestDnUrlPath(t *testing.T) {
+	cases := map[string]string{
+		"uni/tn-EXAMPLE":                        "/api/mo/uni/tn-EXAMPLE.json",
+		"uni/tn-X/BD-Y/subnet-[2001:db8::1/64]": "/api/mo/uni/tn-X/BD-Y/subnet-[2001:db8::1/64].json",
+		"uni/tn-X/ap-A/epg-B%1?#":               "/api/mo/uni/tn-X/ap-A/epg-B%251%3F%23.json",
+		"uni/tn-X/flt-a b":                      "/api/mo/uni/tn-X/flt-a%20b.json",
+	}
+	for dn, path := range cases {
+		if p := dnUrlPath(dn); p != path {
+			t.Fatalf("dnUrlPath(%s): expected %s, got %s", dn, path, p)
+		}
+	}
+	if p := dnParent("uni/tn-X/BD-Y/subnet-[2001:db8::1/64]"); p != "uni/tn-X/BD-Y" {
+		t.Fatalf("dnParent: got %s", p)
+	}
+}
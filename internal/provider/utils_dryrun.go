@@ -0,0 +1,69 @@
+package provider
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/ciscoecosystem/aci-go-client/container"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+)
+
+// jsonLinesMutex serializes writes of resources processed concurrently.
+var jsonLinesMutex sync.Mutex
+
+// dryRunRequest is a request which would have been sent to the APIC.
+type dryRunRequest struct {
+	Method  string          `json:"method"`
+	Path    string          `json:"path"`
+	Payload json.RawMessage `json:"payload,omitempty"`
+}
+
+// appendJsonLine appends a JSON encoded value as a single line to a file.
+func appendJsonLine(path string, value interface{}) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	jsonLinesMutex.Lock()
+	defer jsonLinesMutex.Unlock()
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("Failed to open %s: %s", path, err)
+	}
+	defer f.Close()
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("Failed to write to %s: %s", path, err)
+	}
+	return nil
+}
+
+// writeDryRunRequest records a request instead of sending it to the APIC.
+func writeDryRunRequest(path string, method string, urlPath string, cont *container.Container) error {
+	req := dryRunRequest{Method: method, Path: urlPath}
+	if cont != nil {
+		req.Payload = json.RawMessage(cont.String())
+	}
+	return appendJsonLine(path, req)
+}
+
+// dryRunDiags returns the warning for a request written to the dry-run file. A warning instead of
+// an error lets Terraform continue with dependent objects, so that their requests are written too.
+func dryRunDiags(method string, dn string) diag.Diagnostics {
+	return diag.Diagnostics{{
+		Severity: diag.Warning,
+		Summary:  fmt.Sprintf("%s request to %s written to dry-run file", method, dn),
+		Detail:   "The provider is configured with dry_run_path and the object is not changed. Updated objects keep their prior state. Created objects are added to state with their planned values and are removed from state with the next refresh, as they do not exist.",
+	}}
+}
+
+// dryRunDeleteDiags returns the error for a delete request written to the dry-run file. Deleted
+// objects are always removed from state, therefore the apply fails to keep them in state.
+func dryRunDeleteDiags(dn string) diag.Diagnostics {
+	return diag.Diagnostics{{
+		Severity: diag.Error,
+		Summary:  fmt.Sprintf("DELETE request to %s written to dry-run file", dn),
+		Detail:   "The provider is configured with dry_run_path, the object is not deleted and is kept in state. Objects which depend on it are not processed.",
+	}}
+}
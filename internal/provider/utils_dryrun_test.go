@@ -0,0 +1,49 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func TestApicRestDryRun(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "requests.jsonl")
+	cont, _ := preparePayload("fvTenant", map[string]string{"name": "EXAMPLE"}, nil, true)
+	if err := writeDryRunRequest(path, "POST", "/api/mo/uni/tn-EXAMPLE.json", cont); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	d := schema.TestResourceDataRaw(t, resourceAciRest().Schema, map[string]interface{}{
+		"dn":         "uni/tn-EXAMPLE",
+		"class_name": "fvTenant",
+	})
+	if _, diags := ApicRest(context.Background(), d, apiClient{DryRunPath: path}, "DELETE", false, 0); diags.HasError() {
+		t.Fatalf("unexpected error: %s", diags[0].Summary)
+	}
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 requests, got %d", len(lines))
+	}
+	var req dryRunRequest
+	if err := json.Unmarshal([]byte(lines[0]), &req); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if req.Method != "POST" || req.Path != "/api/mo/uni/tn-EXAMPLE.json" {
+		t.Fatalf("unexpected request: %s %s", req.Method, req.Path)
+	}
+	if payload := string(req.Payload); !strings.Contains(payload, `"name":"EXAMPLE"`) {
+		t.Fatalf("unexpected payload: %s", payload)
+	}
+	if lines[1] != `{"method":"DELETE","path":"/api/mo/uni/tn-EXAMPLE.json"}` {
+		t.Fatalf("unexpected request: %s", lines[1])
+	}
+}
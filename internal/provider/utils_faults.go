@@ -0,0 +1,151 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// restFault is a fault instance ('faultInst') raised on an object or one of its children.
+type restFault struct {
+	Dn        string
+	Code      string
+	Severity  string
+	Lifecycle string
+	Descr     string
+}
+
+// severityLevel returns the position of a severity within FaultSeverities or -1 if unknown.
+func severityLevel(severity string) int {
+	for i, s := range FaultSeverities {
+		if s == severity {
+			return i
+		}
+	}
+	return -1
+}
+
+// decodeFaults returns all fault instances within the decoded objects and their children.
+func decodeFaults(objs []restObject) []restFault {
+	faults := make([]restFault, 0)
+	for _, obj := range objs {
+		if obj.ClassName == "faultInst" {
+			faults = append(faults, restFault{
+				Dn:        obj.Attributes["dn"],
+				Code:      obj.Attributes["code"],
+				Severity:  obj.Attributes["severity"],
+				Lifecycle: obj.Attributes["lc"],
+				Descr:     obj.Attributes["descr"],
+			})
+		}
+		faults = append(faults, decodeFaults(obj.Children)...)
+	}
+	return faults
+}
+
+// faultsPath returns the path to query the faults of an object and its subtree.
+func faultsPath(dn string) string {
+	return dnUrlPath(dn) + "?rsp-subtree=full&rsp-subtree-include=faults,no-scoped"
+}
+
+// queryFaults retrieves the faults returned by a query.
+func queryFaults(ctx context.Context, meta interface{}, path string) ([]restFault, diag.Diagnostics) {
+	objs, diags := queryObjects(ctx, meta, path)
+	if diags.HasError() {
+		return nil, diags
+	}
+	return decodeFaults(objs), nil
+}
+
+// activeFaults returns the faults which are raised or soaking with at least the given severity.
+func activeFaults(faults []restFault, severity string) []restFault {
+	result := make([]restFault, 0)
+	for _, fault := range faults {
+		if fault.Lifecycle == "retaining" || fault.Severity == "cleared" {
+			continue
+		}
+		if severityLevel(fault.Severity) >= severityLevel(severity) {
+			result = append(result, fault)
+		}
+	}
+	return result
+}
+
+// faultCheck polls the faults of an object after it has been written, as the APIC accepts
+// configuration which immediately raises faults, e.g. F0467 for an invalid path.
+func faultCheck(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	checks := d.Get("fault_check").([]interface{})
+	if len(checks) == 0 || checks[0] == nil {
+		return nil
+	}
+	check := checks[0].(map[string]interface{})
+	severity, _ := check["severity"].(string)
+	timeout, _ := check["timeout"].(int)
+	warnOnly, _ := check["warn_only"].(bool)
+	dn := d.Get("dn").(string)
+
+	ctx = logContext(ctx, meta)
+	tflog.Debug(ctx, "Beginning fault check", map[string]interface{}{"id": dn})
+	deadline := time.Now().Add(time.Duration(timeout) * time.Second)
+	for {
+		faults, diags := queryFaults(ctx, meta, faultsPath(dn))
+		if diags.HasError() {
+			return diags
+		}
+		if active := activeFaults(faults, severity); len(active) > 0 {
+			return faultDiags(active, warnOnly)
+		}
+		remaining := time.Until(deadline)
+		if remaining <= 0 {
+			break
+		}
+		if remaining > FaultCheckInterval {
+			remaining = FaultCheckInterval
+		}
+		if err := sleepContext(ctx, remaining); err != nil {
+			return diag.Errorf("Cancelled checking faults of %s: %s", dn, err)
+		}
+	}
+	tflog.Debug(ctx, "Fault check finished successfully", map[string]interface{}{"id": dn})
+	return nil
+}
+
+func faultDiags(faults []restFault, warnOnly bool) diag.Diagnostics {
+	severity := diag.Error
+	if warnOnly {
+		severity = diag.Warning
+	}
+	var diags diag.Diagnostics
+	for _, fault := range faults {
+		diags = append(diags, diag.Diagnostic{
+			Severity: severity,
+			Summary:  fmt.Sprintf("Fault %s (%s) raised on %s", fault.Code, fault.Severity, dnParent(fault.Dn)),
+			Detail:   fault.Descr,
+		})
+	}
+	return diags
+}
+
+// filterFaults returns the faults with at least the given severity and, if not empty, the given
+// code and lifecycle state. Faults which are retaining, and therefore cleared, are only returned
+// if explicitly requested.
+func filterFaults(faults []restFault, severity string, code string, lifecycle string) []restFault {
+	result := make([]restFault, 0)
+	for _, fault := range faults {
+		if fault.Lifecycle != "retaining" && severityLevel(fault.Severity) < severityLevel(severity) {
+			continue
+		}
+		if code != "" && fault.Code != code {
+			continue
+		}
+		if (lifecycle == "" && fault.Lifecycle == "retaining") || (lifecycle != "" && fault.Lifecycle != lifecycle) {
+			continue
+		}
+		result = append(result, fault)
+	}
+	return result
+}
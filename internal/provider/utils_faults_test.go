@@ -0,0 +1,72 @@
+package provider
+
+import (
+	"testing"
+
+	"github.com/ciscoecosystem/aci-go-client/container"
+)
+
+func TestActiveFaults(t *testing.T) {
+	cont, err := container.ParseJSON([]byte(`{"totalCount": "3", "imdata": [
+		{"faultInst": {"attributes": {"dn": "uni/tn-EXAMPLE/ap-AP1/epg-EPG1/fault-F0467", "code": "F0467", "severity": "minor", "lc": "raised", "descr": "Configuration failed"}}},
+		{"faultInst": {"attributes": {"dn": "uni/tn-EXAMPLE/ap-AP1/epg-EPG1/fault-F1228", "code": "F1228", "severity": "critical", "lc": "soaking", "descr": "Invalid VLAN"}}},
+		{"faultInst": {"attributes": {"dn": "uni/tn-EXAMPLE/ap-AP1/epg-EPG1/fault-F0523", "code": "F0523", "severity": "cleared", "lc": "retaining", "descr": "Cleared"}}}
+	]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, diags := decodeResponse(cont)
+	if diags.HasError() {
+		t.Fatalf("unexpected error: %s", diags[0].Summary)
+	}
+	faults := decodeFaults(resp.Objects)
+	if len(faults) != 3 {
+		t.Fatalf("expected 3 faults, got %d", len(faults))
+	}
+	if active := activeFaults(faults, "major"); len(active) != 1 || active[0].Code != "F1228" {
+		t.Fatalf("unexpected active faults: %v", active)
+	}
+	if active := activeFaults(faults, "info"); len(active) != 2 {
+		t.Fatalf("unexpected active faults: %v", active)
+	}
+
+	diags = faultDiags(faults[:1], true)
+	if len(diags) != 1 || diags.HasError() || diags[0].Summary != "Fault F0467 (minor) raised on uni/tn-EXAMPLE/ap-AP1/epg-EPG1" {
+		t.Fatalf("unexpected diagnostics: %v", diags)
+	}
+}
+
+func TestFilterFaults(t *testing.T) {
+	faults := []restFault{
+		{Code: "F0467", Severity: "minor", Lifecycle: "raised"},
+		{Code: "F1228", Severity: "critical", Lifecycle: "soaking"},
+		{Code: "F0523", Severity: "cleared", Lifecycle: "retaining"},
+	}
+	if f := filterFaults(faults, "info", "", ""); len(f) != 2 {
+		t.Fatalf("unexpected faults: %v", f)
+	}
+	if f := filterFaults(faults, "info", "F0467", ""); len(f) != 1 || f[0].Code != "F0467" {
+		t.Fatalf("unexpected faults: %v", f)
+	}
+	if f := filterFaults(faults, "info", "", "retaining"); len(f) != 1 || f[0].Code != "F0523" {
+		t.Fatalf("unexpected faults: %v", f)
+	}
+}
+
+func TestDecodeHealth(t *testing.T) {
+	obj, err := decodeObject("fvTenant", map[string]interface{}{
+		"attributes": map[string]interface{}{"dn": "uni/tn-EXAMPLE"},
+		"children": []interface{}{
+			map[string]interface{}{"healthInst": map[string]interface{}{"attributes": map[string]interface{}{"cur": "95", "prev": "100", "maxSev": "minor"}}},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if health, ok := decodeHealth(obj); !ok || health.Attributes["cur"] != "95" {
+		t.Fatalf("unexpected health: %v", health)
+	}
+	if _, ok := decodeHealth(restObject{ClassName: "fvAp"}); ok {
+		t.Fatalf("expected no health score")
+	}
+}
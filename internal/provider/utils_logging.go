@@ -0,0 +1,93 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"regexp"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// Logging subsystems, the level of each can be set with TF_LOG_PROVIDER_ACI_<SUBSYSTEM>
+const (
+	logHttp   = "http"
+	logRetry  = "retry"
+	logDecode = "decode"
+)
+
+var logSubsystems = []string{logHttp, logRetry, logDecode}
+
+// Field keys whose values are always masked
+var logMaskedFields = []string{"password", "private_key", "private_key_passphrase", "client_key", "token", "cookie"}
+
+// Attributes of payloads whose values are masked, e.g. 'pwd' of aaaUser or 'key' of a RADIUS provider
+var logMaskedAttributes = regexp.MustCompile(`"(\w*([Pp]wd|[Pp]assword|[Kk]ey|[Ss]ecret|[Tt]oken))"\s*:\s*"(?:[^"\\]|\\.)*"`)
+
+type logContextKey struct{}
+
+// logContext returns a context with the logging subsystems of the provider, which mask the
+// configured credentials. Subsystems are only created once for each context.
+func logContext(ctx context.Context, meta interface{}) context.Context {
+	if ctx.Value(logContextKey{}) != nil {
+		return ctx
+	}
+	secrets := make([]string, 0)
+	if cl, ok := meta.(apiClient); ok {
+		for _, secret := range []string{cl.Password, cl.PrivateKeyPassphrase, cl.ClientKey} {
+			if secret != "" {
+				secrets = append(secrets, secret)
+			}
+		}
+	}
+	ctx = maskSecrets(ctx, "", secrets)
+	ctx = tflog.MaskFieldValuesWithFieldKeys(ctx, logMaskedFields...)
+	for _, subsystem := range logSubsystems {
+		ctx = tflog.NewSubsystem(ctx, subsystem, tflog.WithRootFields(), tflog.WithLevelFromEnv("TF_LOG_PROVIDER_ACI", subsystem))
+		ctx = tflog.SubsystemMaskFieldValuesWithFieldKeys(ctx, subsystem, logMaskedFields...)
+		ctx = tflog.SubsystemMaskAllFieldValuesRegexes(ctx, subsystem, logMaskedAttributes)
+		ctx = maskSecrets(ctx, subsystem, secrets)
+	}
+	return context.WithValue(ctx, logContextKey{}, true)
+}
+
+// maskSecrets masks values, e.g. of 'sensitive_content', in all messages and fields of a
+// subsystem or the root logger if the subsystem is empty. Values are also masked as they are
+// encoded in JSON documents, e.g. 'a&b' as 'a\u0026b'.
+func maskSecrets(ctx context.Context, subsystem string, secrets []string) context.Context {
+	values := make([]string, 0, len(secrets))
+	for _, secret := range secrets {
+		if secret == "" {
+			continue
+		}
+		values = append(values, secret)
+		if encoded := jsonEncodedString(secret); encoded != secret {
+			values = append(values, encoded)
+		}
+	}
+	if len(values) == 0 {
+		return ctx
+	}
+	if subsystem == "" {
+		ctx = tflog.MaskAllFieldValuesStrings(ctx, values...)
+		return tflog.MaskMessageStrings(ctx, values...)
+	}
+	ctx = tflog.SubsystemMaskAllFieldValuesStrings(ctx, subsystem, values...)
+	return tflog.SubsystemMaskMessageStrings(ctx, subsystem, values...)
+}
+
+// jsonEncodedString returns a string as it is encoded within a JSON document, without quotes.
+func jsonEncodedString(s string) string {
+	data, err := json.Marshal(s)
+	if err != nil {
+		return s
+	}
+	return string(data[1 : len(data)-1])
+}
+
+// logAttempt adds the number of previous attempts as a field to the logs of all subsystems.
+func logAttempt(ctx context.Context, attempts int) context.Context {
+	for _, subsystem := range logSubsystems {
+		ctx = tflog.SubsystemSetField(ctx, subsystem, "attempt", attempts)
+	}
+	return ctx
+}
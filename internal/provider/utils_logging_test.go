@@ -0,0 +1,104 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/ciscoecosystem/aci-go-client/container"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/hashicorp/terraform-plugin-log/tflogtest"
+)
+
+func TestLogContext(t *testing.T) {
+	var output bytes.Buffer
+	ctx := tflogtest.RootLogger(context.Background(), &output)
+	ctx = logContext(ctx, apiClient{Password: "s3cr3t"})
+	ctx = logAttempt(ctx, 2)
+	ctx = maskSecrets(ctx, logHttp, []string{"topsecret"})
+
+	tflog.SubsystemDebug(ctx, logHttp, "Request payload", map[string]interface{}{
+		"path":    "/api/mo/uni/userext/user-test.json",
+		"payload": `{"aaaUser":{"attributes":{"name":"test","pwd":"P@ssw0rd","descr":"topsecret"}}}`,
+	})
+	tflog.Debug(ctx, "Login with s3cr3t", map[string]interface{}{"password": "s3cr3t"})
+
+	logs := output.String()
+	for _, secret := range []string{"P@ssw0rd", "topsecret", "s3cr3t"} {
+		if strings.Contains(logs, secret) {
+			t.Fatalf("secret %s not masked: %s", secret, logs)
+		}
+	}
+	for _, field := range []string{`"@module":"provider.http"`, `"attempt":2`, `"path":"/api/mo/uni/userext/user-test.json"`} {
+		if !strings.Contains(logs, field) {
+			t.Fatalf("expected %s in logs: %s", field, logs)
+		}
+	}
+
+	// Subsystems are only created once
+	if logContext(ctx, apiClient{}) != ctx {
+		t.Fatalf("expected existing logging context to be returned")
+	}
+}
+
+func TestLogContextJsonEncoded(t *testing.T) {
+	var output bytes.Buffer
+	ctx := tflogtest.RootLogger(context.Background(), &output)
+	ctx = logContext(ctx, apiClient{})
+	ctx = maskSecrets(ctx, logHttp, []string{"pub&lic<1>"})
+
+	// Payloads encode special characters of secrets and quotes within values
+	cont := container.New()
+	cont.Set("pub&lic<1>", "snmpCommunityP", "attributes", "name")
+	cont.Set(`quo"ted`, "aaaUser", "attributes", "pwd")
+	tflog.SubsystemTrace(ctx, logHttp, "Request payload", map[string]interface{}{"payload": cont.String()})
+
+	logs := output.String()
+	for _, secret := range []string{"lic<1>", `u0026lic`, "ted"} {
+		if strings.Contains(logs, secret) {
+			t.Fatalf("secret %s not masked: %s", secret, logs)
+		}
+	}
+}
+
+func TestLoginTokenNotLogged(t *testing.T) {
+	const token = "S3ss10nT0k3n"
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/aaaLogin.json" {
+			// The session has already expired, therefore each request logs in again
+			w.Write([]byte(`{"totalCount": "1", "imdata": [{"aaaLogin": {"attributes": {"token": "` + token + `", "creationTime": "1600000000", "refreshTimeoutSeconds": "600"}}}]}`))
+			return
+		}
+		if cookie, err := r.Cookie("APIC-Cookie"); err != nil || cookie.Value != token {
+			w.WriteHeader(http.StatusForbidden)
+			w.Write([]byte(`{"totalCount": "1", "imdata": [{"error": {"attributes": {"code": "403", "text": "Token was invalid"}}}]}`))
+			return
+		}
+		w.Write([]byte(`{"totalCount": "0", "imdata": []}`))
+	}))
+	defer srv.Close()
+
+	var output bytes.Buffer
+	log.SetOutput(&output)
+	defer log.SetOutput(os.Stderr)
+	ctx := tflogtest.RootLogger(context.Background(), &output)
+
+	cl := apiClient{URL: srv.URL, Username: "admin", Password: "password"}
+	cl.Controllers = newControllers(cl)
+	cont, _ := container.ParseJSON([]byte(`{"fvTenant": {"attributes": {"name": "EXAMPLE"}}}`))
+	for _, method := range []string{"GET", "POST", "GET"} {
+		if _, diags := apicRestRequest(ctx, cl, method, "/api/mo/uni/tn-EXAMPLE.json", cont); diags.HasError() {
+			t.Fatalf("unexpected error: %s", diags[0].Summary)
+		}
+	}
+
+	if logs := output.String(); strings.Contains(logs, token) || strings.Contains(logs, "password") {
+		t.Fatalf("credentials logged: %s", logs)
+	}
+}
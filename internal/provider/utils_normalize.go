@@ -0,0 +1,223 @@
+package provider
+
+import (
+	"net/netip"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// normalizationRule applies a named normalizer to an attribute of a class or, if the class
+// name is empty, to the attribute of all classes.
+type normalizationRule struct {
+	ClassName string
+	Attribute string
+	Rule      string
+}
+
+var macRegexp = regexp.MustCompile(`^([0-9a-fA-F]{2}[:-]){5}[0-9a-fA-F]{2}$`)
+var flagRegexp = regexp.MustCompile(`^[a-zA-Z0-9_-]+$`)
+
+// Named normalizers which can be referenced by custom rules
+var normalizers = map[string]func(string) string{
+	"none":             func(v string) string { return v },
+	"bool":             normalizeBool,
+	"ip":               normalizeIp,
+	"mac":              normalizeMac,
+	"flags":            normalizeFlags,
+	"case_insensitive": strings.ToLower,
+}
+
+// Built-in rules for attributes of common classes, where class specific rules precede the rules of
+// all classes. Values of other attributes are compared as they are, as they may be free text.
+var builtinNormalizationRules = []normalizationRule{
+	{ClassName: "fvSubnet", Attribute: "scope", Rule: "flags"},
+	{ClassName: "l3extSubnet", Attribute: "scope", Rule: "flags"},
+	{ClassName: "l3extSubnet", Attribute: "aggregate", Rule: "flags"},
+	{Attribute: "ctrl", Rule: "flags"},
+	{Attribute: "peerCtrl", Rule: "flags"},
+	{Attribute: "addrTCtrl", Rule: "flags"},
+	{Attribute: "privateASctrl", Rule: "flags"},
+	{Attribute: "areaCtrl", Rule: "flags"},
+	{Attribute: "enforceRtctrl", Rule: "flags"},
+	{Attribute: "arpFlood", Rule: "bool"},
+	{Attribute: "unicastRoute", Rule: "bool"},
+	{Attribute: "limitIpLearnToSubnets", Rule: "bool"},
+	{Attribute: "ipLearning", Rule: "bool"},
+	{Attribute: "mcastAllow", Rule: "bool"},
+	{Attribute: "ipv6McastAllow", Rule: "bool"},
+	{Attribute: "hostBasedRouting", Rule: "bool"},
+	{Attribute: "intersiteBumTrafficAllow", Rule: "bool"},
+	{Attribute: "intersiteL2Stretch", Rule: "bool"},
+	{Attribute: "optimizeWanBandwidth", Rule: "bool"},
+	{Attribute: "epClear", Rule: "bool"},
+	{Attribute: "isAttrBasedEPg", Rule: "bool"},
+	{Attribute: "hasMcastSource", Rule: "bool"},
+	{Attribute: "shutdown", Rule: "bool"},
+	{Attribute: "preferred", Rule: "bool"},
+	{Attribute: "virtual", Rule: "bool"},
+	{Attribute: "ip", Rule: "ip"},
+	{Attribute: "addr", Rule: "ip"},
+	{Attribute: "gw", Rule: "ip"},
+	{Attribute: "v6Addr", Rule: "ip"},
+	{Attribute: "v6Gw", Rule: "ip"},
+	{Attribute: "llAddr", Rule: "ip"},
+	{Attribute: "rtrId", Rule: "ip"},
+	{Attribute: "nhAddr", Rule: "ip"},
+	{Attribute: "mac", Rule: "mac"},
+	{Attribute: "vmac", Rule: "mac"},
+}
+
+// normalizeValue returns the canonical representation of an attribute value. The custom rules
+// configured in the provider take precedence over the built-in rules.
+func normalizeValue(rules []normalizationRule, className, attr, value string) string {
+	for _, ruleSet := range [][]normalizationRule{rules, builtinNormalizationRules} {
+		for _, rule := range ruleSet {
+			if rule.Attribute == attr && (rule.ClassName == "" || rule.ClassName == className) {
+				if f, ok := normalizers[rule.Rule]; ok {
+					return f(value)
+				}
+			}
+		}
+	}
+	return value
+}
+
+// normalizedEqual returns true if both values are equal after normalization.
+func normalizedEqual(rules []normalizationRule, className, attr, a, b string) bool {
+	return a == b || normalizeValue(rules, className, attr, a) == normalizeValue(rules, className, attr, b)
+}
+
+// suppressNormalizedDiff suppresses diffs of content attributes with equivalent values. Attributes
+// which are not configured are ignored, as state of earlier versions contains all attributes of the
+// object. They are removed from state with the next update. Configured attributes which are not in
+// state, e.g. after importing an object without keys, are compared to the attributes read from the
+// APIC. Custom rules are applied by setNormalizedContent, as the provider configuration is not
+// available here.
+func suppressNormalizedDiff(k, old, new string, d *schema.ResourceData) bool {
+	attr := strings.TrimPrefix(k, "content.")
+	if _, ok := d.Get("content").(map[string]interface{})[attr]; !ok {
+		return true
+	}
+	if o, _ := d.GetChange("content"); d.Id() != "" {
+		if _, ok := o.(map[string]interface{})[attr]; !ok {
+			old, _ = d.Get("attributes").(map[string]interface{})[attr].(string)
+		}
+	}
+	if old == "" || new == "" {
+		return false
+	}
+	return normalizedEqual(nil, d.Get("class_name").(string), attr, old, new)
+}
+
+// suppressChildrenDiff suppresses diffs of children with equivalent JSON documents, which have the
+// same class name and equivalent values of the same attributes.
+func suppressChildrenDiff(k, old, new string, d *schema.ResourceData) bool {
+	if strings.HasSuffix(k, ".%") || old == "" || new == "" {
+		return false
+	}
+	return childEqual(strings.TrimPrefix(k, "children."), old, new)
+}
+
+// childEqual returns true if both JSON documents of a child are equivalent according to the built-in rules.
+func childEqual(rn, a, b string) bool {
+	if a == b {
+		return true
+	}
+	childA, err := parseRestChild(rn, a)
+	if err != nil {
+		return false
+	}
+	childB, err := parseRestChild(rn, b)
+	if err != nil || childA.ClassName != childB.ClassName || len(childA.Content) != len(childB.Content) {
+		return false
+	}
+	for attr, value := range childA.Content {
+		if v, ok := childB.Content[attr]; !ok || !normalizedEqual(nil, childA.ClassName, attr, value, v) {
+			return false
+		}
+	}
+	return true
+}
+
+// setNormalizedContent plans the values in state for configured attributes which are equivalent
+// according to the custom rules configured in the provider.
+func setNormalizedContent(d *schema.ResourceDiff, rules []normalizationRule) error {
+	if len(rules) == 0 || d.Id() == "" || !d.NewValueKnown("content") {
+		return nil
+	}
+	className := d.Get("class_name").(string)
+	o, n := d.GetChange("content")
+	oldContent := toStrMap(o.(map[string]interface{}))
+	content := make(map[string]interface{})
+	for attr, value := range oldContent {
+		content[attr] = value
+	}
+	modified := false
+	for attr, value := range toStrMap(n.(map[string]interface{})) {
+		if v, ok := oldContent[attr]; ok && v != value && normalizedEqual(rules, className, attr, v, value) {
+			modified = true
+			continue
+		}
+		content[attr] = value
+	}
+	if !modified {
+		return nil
+	}
+	return d.SetNew("content", content)
+}
+
+// normalizeBool returns 'yes' or 'no' for boolean values.
+func normalizeBool(value string) string {
+	switch strings.ToLower(value) {
+	case "yes", "true":
+		return "yes"
+	case "no", "false":
+		return "no"
+	}
+	return value
+}
+
+// normalizeIp returns the compressed representation of an address or prefix. Unspecified
+// addresses, which the APIC returns for unset attributes, are equivalent to an empty value.
+func normalizeIp(value string) string {
+	if addr, err := netip.ParseAddr(value); err == nil {
+		if addr.IsUnspecified() {
+			return ""
+		}
+		return addr.String()
+	}
+	if prefix, err := netip.ParsePrefix(value); err == nil {
+		return prefix.String()
+	}
+	return value
+}
+
+// normalizeMac returns the upper case, colon-separated representation of a MAC address.
+func normalizeMac(value string) string {
+	if !macRegexp.MatchString(value) {
+		return value
+	}
+	return strings.ToUpper(strings.ReplaceAll(value, "-", ":"))
+}
+
+// normalizeFlags returns a sorted list of comma-separated flags, e.g. 'nd,querier'.
+func normalizeFlags(value string) string {
+	flags := make([]string, 0)
+	for _, flag := range strings.Split(value, ",") {
+		flag = strings.TrimSpace(flag)
+		if flag == "" {
+			continue
+		}
+		if !flagRegexp.MatchString(flag) {
+			return value
+		}
+		if !containsString(flags, flag) {
+			flags = append(flags, flag)
+		}
+	}
+	sort.Strings(flags)
+	return strings.Join(flags, ",")
+}
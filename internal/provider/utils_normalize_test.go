@@ -0,0 +1,52 @@
+package provider
+
+import "testing"
+
+func TestNormalizeValue(t *testing.T) {
+	cases := []struct {
+		className, attr string
+		a, b            string
+		equal           bool
+	}{
+		{"fvBD", "arpFlood", "yes", "true", true},
+		{"fvBD", "arpFlood", "no", "False", true},
+		{"fvBD", "arpFlood", "yes", "no", false},
+		{"fvSubnet", "ip", "2001:DB8:0:0::1/64", "2001:db8::1/64", true},
+		{"l3extRsPathL3OutAtt", "addr", "2001:DB8:0:0::1", "2001:db8::1", true},
+		{"l3extRsNodeL3OutAtt", "rtrId", "0.0.0.0", "", true},
+		{"l3extRsNodeL3OutAtt", "rtrId", "10.0.0.1", "10.0.0.2", false},
+		{"fvBD", "mac", "00:aa:bb:cc:dd:ee", "00:AA:BB:CC:DD:EE", true},
+		{"fvBD", "mac", "00-aa-bb-cc-dd-ee", "00:AA:BB:CC:DD:EE", true},
+		{"fvSubnet", "ctrl", "querier,nd", "nd,querier", true},
+		{"fvSubnet", "ctrl", "querier,nd", "nd", false},
+		{"fvSubnet", "ctrl", "a, b c", "b c, a", false},
+		{"fvSubnet", "scope", "shared,public", "public,shared", true},
+		{"fvTenant", "name", "EXAMPLE", "example", false},
+		// Free text attributes are compared as they are
+		{"fvTenant", "descr", "a,b", "b,a", false},
+		{"fvTenant", "descr", "yes", "true", false},
+		{"fvTenant", "descr", "2001:db8::1", "2001:DB8::1", false},
+	}
+	for _, c := range cases {
+		if equal := normalizedEqual(nil, c.className, c.attr, c.a, c.b); equal != c.equal {
+			t.Fatalf("normalizedEqual(%s.%s, %q, %q): expected %v, got %v", c.className, c.attr, c.a, c.b, c.equal, equal)
+		}
+	}
+
+	rules := []normalizationRule{
+		{ClassName: "fvTenant", Attribute: "name", Rule: "case_insensitive"},
+		{Attribute: "arpFlood", Rule: "none"},
+	}
+	if !normalizedEqual(rules, "fvTenant", "name", "EXAMPLE", "example") {
+		t.Fatalf("expected custom rule to apply")
+	}
+	if normalizedEqual(rules, "fvAp", "name", "EXAMPLE", "example") {
+		t.Fatalf("expected custom rule to only apply to class fvTenant")
+	}
+	if normalizedEqual(rules, "fvBD", "arpFlood", "yes", "true") {
+		t.Fatalf("expected built-in rule to be disabled")
+	}
+	if normalizedEqual(nil, "fvTenant", "name", "EXAMPLE", "example") {
+		t.Fatalf("expected custom rules to only apply to the configured provider")
+	}
+}
@@ -1,9 +1,19 @@
 package provider
 
 import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
 	"github.com/ciscoecosystem/aci-go-client/client"
 	"github.com/ciscoecosystem/aci-go-client/container"
-	"github.com/ciscoecosystem/aci-go-client/models"
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 )
@@ -40,66 +50,373 @@ func preparePayload(className string, inputMap map[string]string, children []int
 	return cont, nil
 }
 
-func ApicRest(d *schema.ResourceData, meta interface{}, method string, children bool) (*container.Container, diag.Diagnostics) {
-	aciClient := meta.(apiClient).Client
-	path := "/api/mo/" + d.Get("dn").(string) + ".json"
-	className := d.Get("class_name").(string)
-	if method == "GET" {
-		if children {
-			path += "?rsp-subtree=children"
-		} else if !containsString(FullClasses, className) {
-			path += "?rsp-prop-include=config-only"
+// resourceGetter is implemented by both schema.ResourceData and schema.ResourceDiff, so payloads
+// can be prepared when planning and applying changes.
+type resourceGetter interface {
+	Get(key string) interface{}
+	GetRawConfigAt(valPath cty.Path) (cty.Value, diag.Diagnostics)
+}
+
+// restPayload prepares the payload of a POST request. If redact is set, sensitive values are
+// replaced by their hashes as stored in state.
+func restPayload(d resourceGetter, meta interface{}, redact bool) (*container.Container, diag.Diagnostics) {
+	contentStrMap, diags := configuredContent(d)
+	if diags.HasError() {
+		return nil, diags
+	}
+	sensitive, diags := sensitiveContent(d)
+	if diags.HasError() {
+		return nil, diags
+	}
+	salt, _ := d.Get("sensitive_salt").(string)
+	for attr, value := range sensitive {
+		if redact {
+			value = hashSensitive(salt, value)
 		}
+		contentStrMap[attr] = value
+	}
+
+	cont, err := preparePayload(d.Get("class_name").(string), contentStrMap, payloadChildren(d), meta.(apiClient).IsAnnotation)
+	if err != nil {
+		return nil, diag.FromErr(err)
+	}
+	return cont, nil
+}
+
+// importPayload prepares the payload of an update of an imported object from its state, which
+// matches the payload planned for a configuration of the imported attributes and children.
+func importPayload(d resourceGetter, meta interface{}) (*container.Container, diag.Diagnostics) {
+	content := toStrMap(d.Get("content").(map[string]interface{}))
+	cont, err := preparePayload(d.Get("class_name").(string), content, payloadChildren(d), meta.(apiClient).IsAnnotation)
+	if err != nil {
+		return nil, diag.FromErr(err)
+	}
+	return cont, nil
+}
+
+// payloadChildren returns the configured children as expected by preparePayload.
+func payloadChildren(d resourceGetter) []interface{} {
+	childrenSet := make([]interface{}, 0, 1)
+	for _, child := range configuredChildren(d) {
+		childMap := make(map[string]interface{})
+		childMap["class_name"] = child.ClassName
+		childMap["content"] = child.Content
+		childrenSet = append(childrenSet, childMap)
+	}
+	return childrenSet
+}
+
+// ApicRest sends a request for the object of a resource or data source, where attempts is the
+// number of previous attempts.
+func ApicRest(ctx context.Context, d *schema.ResourceData, meta interface{}, method string, children bool, attempts int) (*container.Container, diag.Diagnostics) {
+	if method != "GET" && meta.(apiClient).IsReadOnly {
+		return nil, readOnlyDiags(method, d.Get("dn").(string))
+	}
+	path := dnUrlPath(d.Get("dn").(string))
+	if method == "GET" {
+		path += readQuery(d, children)
 	}
 	var cont *container.Container = nil
-	var err error
+	ctx = logAttempt(logContext(ctx, meta), attempts)
+
+	if dryRunPath := meta.(apiClient).DryRunPath; dryRunPath != "" && method != "GET" {
+		var dryRunCont *container.Container
+		if method == "POST" {
+			var diags diag.Diagnostics
+			if cont, diags = restPayload(d, meta, false); diags.HasError() {
+				return nil, diags
+			}
+			// Sensitive values are written as they are stored in state
+			if dryRunCont, diags = restPayload(d, meta, true); diags.HasError() {
+				return nil, diags
+			}
+		}
+		if err := writeDryRunRequest(dryRunPath, method, path, dryRunCont); err != nil {
+			return nil, diag.FromErr(err)
+		}
+		return cont, nil
+	}
 
 	if method == "POST" {
-		content := d.Get("content")
-		contentStrMap := toStrMap(content.(map[string]interface{}))
+		var diags diag.Diagnostics
+		if cont, diags = restPayload(d, meta, false); diags.HasError() {
+			return nil, diags
+		}
+		sensitive, _ := sensitiveContent(d)
+		secrets := make([]string, 0, len(sensitive))
+		for _, value := range sensitive {
+			secrets = append(secrets, value)
+		}
+		ctx = maskSecrets(ctx, logHttp, secrets)
+	}
 
-		childrenSet := make([]interface{}, 0, 1)
+	respCont, status, diags := apicRestExchange(ctx, meta, method, path, cont)
+	if auditLogPath := meta.(apiClient).AuditLogPath; auditLogPath != "" && method != "GET" {
+		if err := writeAuditRecord(auditLogPath, d, meta, method, respCont, status, diags, attempts); err != nil {
+			diags = append(diags, auditLogDiags(err)...)
+		}
+	}
+	if respCont == nil || diags.HasError() {
+		return respCont, diags
+	}
+	if method == "POST" {
+		return cont, diags
+	} else {
+		return respCont, diags
+	}
+}
+
+// readOnlyDiags returns the error for a request refused because the provider is read-only.
+func readOnlyDiags(method string, dn string) diag.Diagnostics {
+	return diag.Diagnostics{{
+		Severity: diag.Error,
+		Summary:  fmt.Sprintf("Refusing %s request to %s in read-only mode", method, dn),
+		Detail:   "The provider is configured with read_only, objects cannot be created, updated or deleted.",
+	}}
+}
 
-		for _, child := range d.Get("child").(*schema.Set).List() {
-			childMap := make(map[string]interface{})
-			childClassName := child.(map[string]interface{})["class_name"]
-			childContent := child.(map[string]interface{})["content"]
-			childMap["class_name"] = childClassName.(string)
-			childMap["content"] = toStrMap(childContent.(map[string]interface{}))
-			childrenSet = append(childrenSet, childMap)
+// restChild is a child object configured either by a 'child' block or a 'children' map entry,
+// where the latter is a JSON document with 'class_name' and 'content' keys.
+type restChild struct {
+	Rn        string            `json:"-"`
+	ClassName string            `json:"class_name"`
+	Content   map[string]string `json:"content,omitempty"`
+}
+
+func parseRestChild(rn string, value string) (restChild, error) {
+	child := restChild{Rn: rn}
+	if err := json.Unmarshal([]byte(value), &child); err != nil {
+		return child, fmt.Errorf("Invalid child %s, expected a JSON object with 'class_name' and 'content': %s", rn, err)
+	}
+	if child.ClassName == "" {
+		return child, fmt.Errorf("Invalid child %s, 'class_name' must be provided", rn)
+	}
+	return child, nil
+}
+
+func (c restChild) String() string {
+	data, _ := json.Marshal(c)
+	return string(data)
+}
+
+// configuredContent returns the attributes of 'content' from the raw configuration, as the planned
+// value also contains attributes which are no longer configured, see suppressNormalizedDiff.
+func configuredContent(d resourceGetter) (map[string]string, diag.Diagnostics) {
+	content := make(map[string]string)
+	value, diags := d.GetRawConfigAt(cty.GetAttrPath("content"))
+	if diags.HasError() {
+		return nil, diags
+	}
+	if value.IsKnown() && !value.IsNull() {
+		for attr, v := range value.AsValueMap() {
+			if v.IsKnown() && !v.IsNull() {
+				content[attr] = v.AsString()
+			}
 		}
+	}
+	return content, nil
+}
 
-		cont, err = preparePayload(className, contentStrMap, childrenSet, meta.(apiClient).IsAnnotation)
-		if err != nil {
-			return nil, diag.FromErr(err)
+// configuredChildren returns the children of both the 'child' set and the 'children' map.
+func configuredChildren(d resourceGetter) []restChild {
+	children := make([]restChild, 0)
+	if childSet, ok := d.Get("child").(*schema.Set); ok {
+		for _, child := range childSet.List() {
+			childMap := child.(map[string]interface{})
+			childRn, _ := childMap["rn"].(string)
+			childClassName, _ := childMap["class_name"].(string)
+			childContent, _ := childMap["content"].(map[string]interface{})
+			children = append(children, restChild{Rn: childRn, ClassName: childClassName, Content: toStrMap(childContent)})
+		}
+	}
+	if childrenMap, ok := d.Get("children").(map[string]interface{}); ok {
+		rns := make([]string, 0, len(childrenMap))
+		for rn := range childrenMap {
+			rns = append(rns, rn)
+		}
+		sort.Strings(rns)
+		for _, rn := range rns {
+			// Invalid values are rejected during validation
+			if child, err := parseRestChild(rn, childrenMap[rn].(string)); err == nil {
+				children = append(children, child)
+			}
 		}
 	}
+	return children
+}
 
-	req, err := aciClient.MakeRestRequest(method, path, cont, true)
-	if err != nil {
-		return nil, diag.FromErr(err)
+// readQuery returns the query of a read, optionally including children. Only the configurable
+// properties of resources are read, except for classes in FullClasses. The class of data sources
+// is not known before reading, therefore they read all properties.
+func readQuery(d *schema.ResourceData, children bool) string {
+	query := make([]string, 0)
+	if children {
+		query = append(query, "rsp-subtree=children"+childrenQuery(d))
 	}
-	respCont, _, err := aciClient.Do(req)
-	if err != nil {
-		return respCont, diag.FromErr(err)
+	if className := d.Get("class_name").(string); className != "" && !containsString(FullClasses, className) {
+		query = append(query, "rsp-prop-include=config-only")
+	}
+	if len(query) == 0 {
+		return ""
 	}
-	if respCont.S("imdata").Index(0).String() == "{}" {
-		return nil, nil
+	return "?" + strings.Join(query, "&")
+}
+
+// childrenQuery restricts the children returned by a query to the classes of the configured
+// children and, for a limited number of children, to their distinguished names. If the class
+// of any child is unknown, all children are returned.
+func childrenQuery(d *schema.ResourceData) string {
+	dn := d.Get("dn").(string)
+	children := configuredChildren(d)
+	classes := make([]string, 0)
+	filters := make([]string, 0)
+	for _, child := range children {
+		if child.ClassName == "" {
+			return ""
+		}
+		if !containsString(classes, child.ClassName) {
+			classes = append(classes, child.ClassName)
+		}
+		if child.Rn != "" && !strings.Contains(child.Rn, `"`) {
+			filters = append(filters, fmt.Sprintf(`eq(%s.dn,"%s/%s")`, child.ClassName, dn, child.Rn))
+		}
+	}
+	if len(classes) == 0 {
+		return ""
+	}
+	sort.Strings(classes)
+	query := "&rsp-subtree-class=" + strings.Join(classes, ",")
+	if len(filters) == len(children) && len(filters) <= MaxChildrenFilter {
+		sort.Strings(filters)
+		filter := filters[0]
+		if len(filters) > 1 {
+			filter = "or(" + strings.Join(filters, ",") + ")"
+		}
+		query += "&rsp-subtree-filter=" + url.QueryEscape(filter)
+	}
+	return query
+}
+
+// apicRestRequest sends a single request to the APIC and checks the response for errors.
+// A nil container without diagnostics is returned if the response does not contain any objects.
+func apicRestRequest(ctx context.Context, meta interface{}, method string, path string, cont *container.Container) (*container.Container, diag.Diagnostics) {
+	respCont, _, diags := apicRestExchange(ctx, meta, method, path, cont)
+	return respCont, diags
+}
+
+// apicRestExchange is apicRestRequest which additionally returns the HTTP status code of the
+// response, or zero if no response was received.
+func apicRestExchange(ctx context.Context, meta interface{}, method string, path string, cont *container.Container) (*container.Container, int, diag.Diagnostics) {
+	ctx = logContext(ctx, meta)
+	cl := meta.(apiClient)
+	ctrls := cl.Controllers
+	var respCont *container.Container
+	var httpResp *http.Response
+	status := 0
+	if cont != nil {
+		tflog.SubsystemTrace(ctx, logHttp, "Request payload", map[string]interface{}{"method": method, "path": path, "payload": cont.String()})
 	}
-	err = client.CheckForErrors(respCont, method, false)
+	for attempts := 1; ; attempts++ {
+		index, aciClient := ctrls.current()
+		start := time.Now()
+		req, err := cl.newRequest(ctx, aciClient, method, path, cont)
+		if err == nil {
+			respCont, httpResp, err = aciClient.Do(req.WithContext(ctx))
+		}
+		fields := map[string]interface{}{
+			"method":      method,
+			"path":        path,
+			"controller":  ctrls.urls[index],
+			"duration_ms": time.Since(start).Milliseconds(),
+		}
+		if httpResp != nil {
+			status = httpResp.StatusCode
+			fields["status"] = status
+		}
+		if err == nil {
+			tflog.SubsystemDebug(ctx, logHttp, "Request finished", fields)
+			break
+		}
+		fields["error"] = err.Error()
+		if attempts >= ctrls.count() || !isConnectionError(ctx, err) {
+			tflog.SubsystemError(ctx, logHttp, "Request failed", fields)
+			return respCont, status, diag.FromErr(err)
+		}
+		if next := ctrls.failover(index); next != "" {
+			fields["next_controller"] = next
+			tflog.SubsystemWarn(ctx, logHttp, "Controller unreachable, failing over", fields)
+		}
+	}
+	if respCont != nil {
+		tflog.SubsystemTrace(ctx, logHttp, "Response payload", map[string]interface{}{"method": method, "path": path, "response": respCont.String()})
+	}
+	resp, diags := decodeResponse(respCont)
+	if diags.HasError() {
+		tflog.SubsystemError(ctx, logDecode, "Failed to decode response", map[string]interface{}{"path": path, "error": diags[0].Summary})
+		return respCont, status, diags
+	}
+	tflog.SubsystemTrace(ctx, logDecode, "Decoded response", map[string]interface{}{"path": path, "total_count": resp.TotalCount, "objects": len(resp.Objects)})
+	if len(resp.Objects) == 0 && resp.Error == nil {
+		return nil, status, nil
+	}
+	err := client.CheckForErrors(respCont, method, false)
 	if err != nil {
-		if method == "DELETE" {
-			errCode := models.StripQuotes(models.StripSquareBrackets(respCont.Search("imdata", "error", "attributes", "code").String()))
-			// Ignore errors of type "Cannot delete object"
-			if errCode == "1" || errCode == "107" {
-				return respCont, nil
+		// Ignore errors of type "Cannot delete object"
+		if method == "DELETE" && resp.Error != nil && (resp.Error.Code == "1" || resp.Error.Code == "107") {
+			return respCont, status, nil
+		}
+		fields := map[string]interface{}{"method": method, "path": path, "status": status}
+		if resp.Error != nil {
+			fields["error_code"] = resp.Error.Code
+			fields["error"] = resp.Error.Text
+		}
+		tflog.SubsystemError(ctx, logHttp, "APIC returned an error", fields)
+		return respCont, status, diag.FromErr(err)
+	}
+	return respCont, status, nil
+}
+
+// queryObjects retrieves the objects returned by a query, retrying failed requests.
+func queryObjects(ctx context.Context, meta interface{}, path string) ([]restObject, diag.Diagnostics) {
+	ctx = logContext(ctx, meta)
+	for attempts := 0; ; attempts++ {
+		cont, diags := apicRestRequest(logAttempt(ctx, attempts), meta, "GET", path, nil)
+		if !diags.HasError() {
+			if cont == nil {
+				return make([]restObject, 0), nil
 			}
+			resp, diags := decodeResponse(cont)
+			if diags.HasError() {
+				return nil, diags
+			}
+			return resp.Objects, nil
+		}
+		if ok := backoff(ctx, attempts, meta.(apiClient).Retries); !ok {
+			return nil, diags
 		}
-		return respCont, diag.FromErr(err)
+		tflog.SubsystemWarn(ctx, logRetry, "Failed to query objects", map[string]interface{}{"path": path, "error": diags[0].Summary, "attempt": attempts})
 	}
-	if method == "POST" {
-		return cont, nil
-	} else {
-		return respCont, nil
+}
+
+// discoverClassName retrieves the class name of an existing object.
+func discoverClassName(ctx context.Context, meta interface{}, dn string) (string, diag.Diagnostics) {
+	ctx = logContext(ctx, meta)
+	path := dnUrlPath(dn) + "?rsp-prop-include=naming-only"
+	for attempts := 0; ; attempts++ {
+		cont, diags := apicRestRequest(logAttempt(ctx, attempts), meta, "GET", path, nil)
+		if !diags.HasError() {
+			if cont == nil {
+				return "", diag.Errorf("Object %s not found", dn)
+			}
+			obj, diags := decodeSingleObject(cont, "")
+			if diags.HasError() {
+				return "", diags
+			}
+			return obj.ClassName, nil
+		}
+		if ok := backoff(ctx, attempts, meta.(apiClient).Retries); !ok {
+			return "", diags
+		}
+		tflog.SubsystemWarn(ctx, logRetry, "Failed to discover class of object", map[string]interface{}{"dn": dn, "error": diags[0].Summary, "attempt": attempts})
 	}
 }
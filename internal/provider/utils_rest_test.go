@@ -0,0 +1,93 @@
+package provider
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func TestChildrenQuery(t *testing.T) {
+	d := schema.TestResourceDataRaw(t, resourceAciRest().Schema, map[string]interface{}{
+		"dn":         "uni/tn-EXAMPLE",
+		"class_name": "fvTenant",
+		"child": []interface{}{
+			map[string]interface{}{"rn": "ctx-VRF1", "class_name": "fvCtx"},
+			map[string]interface{}{"rn": "BD-BD1", "class_name": "fvBD"},
+		},
+	})
+	expected := "&rsp-subtree-class=fvBD,fvCtx&rsp-subtree-filter=" +
+		"or%28eq%28fvBD.dn%2C%22uni%2Ftn-EXAMPLE%2FBD-BD1%22%29%2Ceq%28fvCtx.dn%2C%22uni%2Ftn-EXAMPLE%2Fctx-VRF1%22%29%29"
+	if q := childrenQuery(d); q != expected {
+		t.Fatalf("expected %s, got %s", expected, q)
+	}
+
+	d = schema.TestResourceDataRaw(t, resourceAciRest().Schema, map[string]interface{}{
+		"dn":         "uni/tn-EXAMPLE",
+		"class_name": "fvTenant",
+		"child": []interface{}{
+			map[string]interface{}{"rn": "ctx-VRF1", "class_name": "fvCtx"},
+			map[string]interface{}{"rn": "BD-BD1"},
+		},
+	})
+	if q := childrenQuery(d); q != "" {
+		t.Fatalf("expected no restriction for children without class, got %s", q)
+	}
+}
+
+func TestReadQuery(t *testing.T) {
+	d := schema.TestResourceDataRaw(t, resourceAciRest().Schema, map[string]interface{}{
+		"dn":         "uni/tn-EXAMPLE",
+		"class_name": "fvTenant",
+	})
+	if q := readQuery(d, false); q != "?rsp-prop-include=config-only" {
+		t.Fatalf("expected only configurable properties to be read, got %s", q)
+	}
+	if q := readQuery(d, true); q != "?rsp-subtree=children&rsp-prop-include=config-only" {
+		t.Fatalf("expected only configurable properties of children to be read, got %s", q)
+	}
+
+	d = schema.TestResourceDataRaw(t, resourceAciRest().Schema, map[string]interface{}{
+		"dn":         "uni/fabric/fwgrp-EXAMPLE",
+		"class_name": "firmwareFwGrp",
+	})
+	if q := readQuery(d, false); q != "" {
+		t.Fatalf("expected all properties of %s to be read, got %s", "firmwareFwGrp", q)
+	}
+
+	d = schema.TestResourceDataRaw(t, dataSourceAciRest().Schema, map[string]interface{}{
+		"dn": "uni/tn-EXAMPLE",
+	})
+	if q := readQuery(d, true); q != "?rsp-subtree=children" {
+		t.Fatalf("expected all properties to be read by data source, got %s", q)
+	}
+}
+
+func TestParseRestChild(t *testing.T) {
+	child, err := parseRestChild("ctx-VRF1", `{"content": {"name": "VRF1"}, "class_name": "fvCtx"}`)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if s := child.String(); s != `{"class_name":"fvCtx","content":{"name":"VRF1"}}` {
+		t.Fatalf("unexpected normalized child: %s", s)
+	}
+	if _, err := parseRestChild("ctx-VRF1", `{"content": {"name": "VRF1"}}`); err == nil {
+		t.Fatalf("expected error for missing class_name")
+	}
+}
+
+func TestApicRestReadOnly(t *testing.T) {
+	d := schema.TestResourceDataRaw(t, resourceAciRest().Schema, map[string]interface{}{
+		"dn":         "uni/tn-EXAMPLE",
+		"class_name": "fvTenant",
+	})
+	meta := apiClient{IsReadOnly: true}
+	for _, method := range []string{"POST", "DELETE"} {
+		if _, diags := ApicRest(context.Background(), d, meta, method, false, 0); !diags.HasError() {
+			t.Fatalf("expected %s request to be refused", method)
+		}
+	}
+	if diags := resourceAciRestDelete(context.Background(), d, meta); !diags.HasError() {
+		t.Fatalf("expected delete to be refused")
+	}
+}
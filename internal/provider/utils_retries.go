@@ -1,13 +1,19 @@
 package provider
 
 import (
+	"context"
 	"math"
 	"math/rand"
 	"time"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
 )
 
-func backoff(attempts int, maxRetries int) bool {
-	if attempts > maxRetries {
+// backoff waits before the next attempt and returns false if no more attempts should be made,
+// either because the maximum number of retries is reached or the context is cancelled.
+func backoff(ctx context.Context, attempts int, maxRetries int) bool {
+	if attempts > maxRetries || ctx.Err() != nil {
+		tflog.SubsystemDebug(ctx, logRetry, "Giving up", map[string]interface{}{"attempt": attempts, "max_retries": maxRetries})
 		return false
 	}
 	min := float64(MinDelay)
@@ -16,6 +22,18 @@ func backoff(attempts int, maxRetries int) bool {
 		backoff = float64(MaxDelay)
 	}
 	backoff = (rand.Float64()/2+0.5)*(backoff-min) + min
-	time.Sleep(time.Duration(backoff))
-	return true
+	tflog.SubsystemDebug(ctx, logRetry, "Waiting before next attempt", map[string]interface{}{"attempt": attempts, "max_retries": maxRetries, "delay_ms": time.Duration(backoff).Milliseconds()})
+	return sleepContext(ctx, time.Duration(backoff)) == nil
+}
+
+// sleepContext pauses for the given duration or until the context is cancelled.
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
 }
@@ -0,0 +1,25 @@
+package provider
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestBackoffContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	start := time.Now()
+	if backoff(ctx, 0, 3) {
+		t.Fatalf("expected no retry with cancelled context")
+	}
+	if time.Since(start) >= MinDelay {
+		t.Fatalf("expected backoff to return immediately")
+	}
+
+	ctx, cancel = context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := sleepContext(ctx, time.Minute); err != context.DeadlineExceeded {
+		t.Fatalf("expected deadline exceeded, got %v", err)
+	}
+}
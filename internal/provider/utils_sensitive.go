@@ -0,0 +1,145 @@
+package provider
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"regexp"
+
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+var sensitiveHashRegexp = regexp.MustCompile(`^hmac-sha256:[0-9a-f]{64}$`)
+
+// hashSensitive returns the value stored in state instead of a sensitive value, which is an
+// HMAC-SHA256 keyed with the random salt of the resource, so that equal values of different
+// resources have different hashes and cannot be looked up in precomputed tables.
+func hashSensitive(salt string, value string) string {
+	if sensitiveHashRegexp.MatchString(value) {
+		return value
+	}
+	mac := hmac.New(sha256.New, []byte(salt))
+	mac.Write([]byte(value))
+	return "hmac-sha256:" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// newSensitiveSalt returns a random salt for the hashes of sensitive values of a resource.
+func newSensitiveSalt() (string, error) {
+	salt := make([]byte, 32)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(salt), nil
+}
+
+// setSensitiveSalt plans a salt when sensitive values are configured for the first time. Resources
+// without sensitive values have no salt, so that their imported state matches.
+func setSensitiveSalt(d *schema.ResourceDiff) error {
+	if d.Get("sensitive_salt").(string) != "" || len(d.Get("sensitive_content").(map[string]interface{})) == 0 {
+		return nil
+	}
+	salt, err := newSensitiveSalt()
+	if err != nil {
+		return err
+	}
+	return d.SetNew("sensitive_salt", salt)
+}
+
+// suppressSensitiveDiff compares a hashed value from state with a configured value.
+func suppressSensitiveDiff(k, old, new string, d *schema.ResourceData) bool {
+	if old == "" || new == "" {
+		return false
+	}
+	salt := d.Get("sensitive_salt").(string)
+	return hashSensitive(salt, old) == hashSensitive(salt, new)
+}
+
+// sensitiveContentChanged returns true if the hash of a configured value differs from the hash in
+// state. The planned values are not hashed, therefore HasChange always reports a change.
+func sensitiveContentChanged(d *schema.ResourceDiff) bool {
+	if !d.NewValueKnown("sensitive_content") {
+		return true
+	}
+	o, n := d.GetChange("sensitive_content")
+	oldValues := toStrMap(o.(map[string]interface{}))
+	newValues := toStrMap(n.(map[string]interface{}))
+	if len(oldValues) != len(newValues) {
+		return true
+	}
+	salt, _ := d.GetChange("sensitive_salt")
+	for attr, value := range newValues {
+		if v, ok := oldValues[attr]; !ok || hashSensitive(salt.(string), v) != hashSensitive(salt.(string), value) {
+			return true
+		}
+	}
+	return false
+}
+
+// setSensitiveContentHashes replaces the values of 'sensitive_content' by their hashes, as secret
+// attributes are never returned by the APIC and must not be stored in state.
+func setSensitiveContentHashes(d *schema.ResourceData) {
+	salt := d.Get("sensitive_salt").(string)
+	hashes := make(map[string]interface{})
+	for attr, value := range d.Get("sensitive_content").(map[string]interface{}) {
+		if v, ok := value.(string); ok {
+			hashes[attr] = hashSensitive(salt, v)
+		}
+	}
+	d.Set("sensitive_content", hashes)
+}
+
+// sensitiveContent returns the cleartext values of 'sensitive_content' and 'sensitive_content_wo'
+// from the raw configuration, as planned values of unchanged attributes only contain hashes.
+func sensitiveContent(d resourceGetter) (map[string]string, diag.Diagnostics) {
+	result := make(map[string]string)
+
+	value, diags := d.GetRawConfigAt(cty.GetAttrPath("sensitive_content"))
+	if diags.HasError() {
+		return nil, diags
+	}
+	if value.IsKnown() && !value.IsNull() {
+		for attr, v := range value.AsValueMap() {
+			if v.IsKnown() && !v.IsNull() {
+				result[attr] = v.AsString()
+			}
+		}
+	}
+
+	value, diags = d.GetRawConfigAt(cty.GetAttrPath("sensitive_content_wo"))
+	if diags.HasError() {
+		return nil, diags
+	}
+	if value.IsKnown() && !value.IsNull() {
+		wo, err := parseSensitiveContentWo(value.AsString())
+		if err != nil {
+			return nil, diag.FromErr(err)
+		}
+		for attr, v := range wo {
+			result[attr] = v
+		}
+	}
+	return result, nil
+}
+
+func parseSensitiveContentWo(value string) (map[string]string, error) {
+	result := make(map[string]string)
+	if err := json.Unmarshal([]byte(value), &result); err != nil {
+		return nil, fmt.Errorf("Invalid sensitive_content_wo, expected a JSON object with string values: %s", err)
+	}
+	return result, nil
+}
+
+func validateSensitiveContentWo(val interface{}, key string) (warns []string, errs []error) {
+	if val.(string) == UnknownValue {
+		return
+	}
+	if _, err := parseSensitiveContentWo(val.(string)); err != nil {
+		errs = append(errs, fmt.Errorf("%q: %s", key, err))
+	}
+	return
+}
@@ -0,0 +1,128 @@
+package provider
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/youmark/pkcs8"
+)
+
+// requestSigner signs requests with the private key of a user certificate, as an alternative to
+// password authentication which does not require a session.
+type requestSigner struct {
+	key    crypto.Signer
+	certDn string
+}
+
+// newSigner returns a signer for the configured private key or nil if password authentication is used.
+func (c apiClient) newSigner() (*requestSigner, error) {
+	if c.Password != "" || c.PrivateKey == "" {
+		return nil, nil
+	}
+	key, err := parsePrivateKey(c.PrivateKey, c.PrivateKeyPassphrase)
+	if err != nil {
+		return nil, err
+	}
+	s := &requestSigner{key: key, certDn: fmt.Sprintf("uni/userext/user-%s/usercert-%s", c.Username, c.Certname)}
+	// Make sure the key is usable before sending any requests
+	if _, err := s.sign([]byte("GET/api/class/topSystem.json")); err != nil {
+		return nil, fmt.Errorf("Failed to sign test request with private_key: %s", err)
+	}
+	return s, nil
+}
+
+// parsePrivateKey parses an RSA or EC private key, which is either provided inline or read from a
+// file. Encrypted PKCS#8 keys are decrypted with the passphrase, where PBES2 with PBKDF2 or scrypt
+// and AES-CBC or 3DES is supported.
+func parsePrivateKey(value string, passphrase string) (crypto.Signer, error) {
+	data, err := readPem(value)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("Failed to decode private key, expected PEM format")
+	}
+	var key interface{}
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		key, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "EC PRIVATE KEY":
+		key, err = x509.ParseECPrivateKey(block.Bytes)
+	case "PRIVATE KEY":
+		key, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "ENCRYPTED PRIVATE KEY":
+		if passphrase == "" {
+			return nil, errors.New("Private key is encrypted, private_key_passphrase must be provided")
+		}
+		if key, err = pkcs8.ParsePKCS8PrivateKey(block.Bytes, []byte(passphrase)); err != nil {
+			return nil, fmt.Errorf("Failed to decrypt private key: %s", err)
+		}
+	default:
+		return nil, fmt.Errorf("Unsupported private key type %q", block.Type)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("Failed to parse private key: %s", err)
+	}
+	switch k := key.(type) {
+	case *rsa.PrivateKey:
+		return k, nil
+	case *ecdsa.PrivateKey:
+		return k, nil
+	}
+	return nil, errors.New("Unsupported private key, only RSA and EC keys are supported")
+}
+
+// sign returns the base64 encoded signature of the SHA-256 hash of the content.
+func (s *requestSigner) sign(content []byte) (string, error) {
+	digest := sha256.Sum256(content)
+	var signature []byte
+	var err error
+	switch key := s.key.(type) {
+	case *ecdsa.PrivateKey:
+		signature, err = ecdsa.SignASN1(rand.Reader, key, digest[:])
+	default:
+		signature, err = s.key.Sign(rand.Reader, digest[:], crypto.SHA256)
+	}
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(signature), nil
+}
+
+// signRequest adds the signature cookies to a request, where the signed content consists of the
+// method, the path including the query and the payload.
+func (s *requestSigner) signRequest(req *http.Request, path string) error {
+	var body []byte
+	if req.Body != nil {
+		var err error
+		if body, err = ioutil.ReadAll(req.Body); err != nil {
+			return err
+		}
+		req.Body = ioutil.NopCloser(bytes.NewBuffer(body))
+	}
+	content := req.Method + path
+	if string(body) != "{}" {
+		content += string(body)
+	}
+	signature, err := s.sign([]byte(content))
+	if err != nil {
+		return err
+	}
+	req.AddCookie(&http.Cookie{Name: "APIC-Request-Signature", Value: signature})
+	req.AddCookie(&http.Cookie{Name: "APIC-Certificate-Algorithm", Value: "v1.0"})
+	req.AddCookie(&http.Cookie{Name: "APIC-Certificate-Fingerprint", Value: "fingerprint"})
+	req.AddCookie(&http.Cookie{Name: "APIC-Certificate-DN", Value: s.certDn})
+	return nil
+}
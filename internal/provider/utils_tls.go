@@ -0,0 +1,78 @@
+package provider
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// readPem returns PEM content which is either provided inline or read from a file.
+func readPem(value string) ([]byte, error) {
+	if strings.Contains(value, "-----BEGIN") {
+		return []byte(value), nil
+	}
+	data, err := ioutil.ReadFile(value)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to read PEM file %s: %s", value, err)
+	}
+	return data, nil
+}
+
+// tlsConfigured returns true if any setting requires a custom HTTP transport.
+func (c apiClient) tlsConfigured() bool {
+	return c.CaCertificate != "" || c.TlsServerName != "" || c.ClientCertificate != ""
+}
+
+// newHttpClient returns an HTTP client with a transport which verifies the APIC certificate
+// with the configured CA and presents a client certificate, e.g. to a mTLS proxy. If a CA
+// certificate or server name is provided, the APIC certificate is always verified.
+func (c apiClient) newHttpClient() (*http.Client, error) {
+	tlsConfig := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		ServerName:         c.TlsServerName,
+		InsecureSkipVerify: c.IsInsecure && c.CaCertificate == "" && c.TlsServerName == "",
+	}
+	if c.CaCertificate != "" {
+		ca, err := readPem(c.CaCertificate)
+		if err != nil {
+			return nil, err
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(ca) {
+			return nil, fmt.Errorf("Failed to parse CA certificate")
+		}
+		tlsConfig.RootCAs = pool
+	}
+	if c.ClientCertificate != "" {
+		cert, err := readPem(c.ClientCertificate)
+		if err != nil {
+			return nil, err
+		}
+		key, err := readPem(c.ClientKey)
+		if err != nil {
+			return nil, err
+		}
+		pair, err := tls.X509KeyPair(cert, key)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to load client certificate: %s", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{pair}
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = tlsConfig
+	// The default transport might have been modified by other clients
+	transport.Proxy = http.ProxyFromEnvironment
+	if c.ProxyUrl != "" {
+		proxyUrl, err := url.Parse(c.ProxyUrl)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid proxy URL %s: %s", c.ProxyUrl, err)
+		}
+		transport.Proxy = http.ProxyURL(proxyUrl)
+	}
+	return &http.Client{Transport: transport}, nil
+}
@@ -0,0 +1,44 @@
+package provider
+
+import (
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestNewHttpClient(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+	ca := string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw}))
+
+	cases := []struct {
+		cl apiClient
+		ok bool
+	}{
+		{apiClient{IsInsecure: true, CaCertificate: ca}, true},
+		{apiClient{IsInsecure: true, CaCertificate: ca, TlsServerName: "example.com"}, true},
+		{apiClient{IsInsecure: true, CaCertificate: ca, TlsServerName: "apic.example.org"}, false},
+		{apiClient{IsInsecure: false, TlsServerName: "example.com"}, false},
+		// The server name enables verification regardless of insecure
+		{apiClient{IsInsecure: true, TlsServerName: "example.com"}, false},
+		{apiClient{IsInsecure: true}, true},
+	}
+	for _, c := range cases {
+		httpClient, err := c.cl.newHttpClient()
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		resp, err := httpClient.Get(srv.URL)
+		if err == nil {
+			resp.Body.Close()
+		}
+		if (err == nil) != c.ok {
+			t.Fatalf("server name %q: expected success %v, got %v", c.cl.TlsServerName, c.ok, err)
+		}
+	}
+
+	if _, err := (apiClient{CaCertificate: "-----BEGIN CERTIFICATE-----\ninvalid\n-----END CERTIFICATE-----"}).newHttpClient(); err == nil {
+		t.Fatalf("expected error for invalid CA certificate")
+	}
+}
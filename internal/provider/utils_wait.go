@@ -0,0 +1,121 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// waitCondition is a 'wait_for' block, which queries either an object by its distinguished name,
+// all objects of a class or all objects of a class within the subtree of an object.
+type waitCondition struct {
+	Dn         string
+	ClassName  string
+	Filter     string
+	Attributes map[string]string
+	Timeout    time.Duration
+}
+
+func (w waitCondition) path() string {
+	query := make([]string, 0)
+	var path string
+	if w.Dn != "" {
+		path = dnUrlPath(w.Dn)
+		if w.ClassName != "" {
+			query = append(query, "query-target=subtree", "target-subtree-class="+w.ClassName)
+		}
+	} else {
+		path = "/api/class/" + url.PathEscape(w.ClassName) + ".json"
+	}
+	if w.Filter != "" {
+		query = append(query, "query-target-filter="+url.QueryEscape(w.Filter))
+	}
+	if len(query) > 0 {
+		path += "?" + strings.Join(query, "&")
+	}
+	return path
+}
+
+// unmetAttributes returns a description of the attributes which do not have the expected values,
+// which is empty if all objects match. At least one object is required.
+func (w waitCondition) unmetAttributes(objs []restObject, rules []normalizationRule) string {
+	if len(objs) == 0 {
+		return "no matching objects"
+	}
+	attrs := make([]string, 0, len(w.Attributes))
+	for attr := range w.Attributes {
+		attrs = append(attrs, attr)
+	}
+	sort.Strings(attrs)
+	unmet := make([]string, 0)
+	for _, obj := range objs {
+		for _, attr := range attrs {
+			expected := w.Attributes[attr]
+			if v := obj.Attributes[attr]; !normalizedEqual(rules, obj.ClassName, attr, v, expected) {
+				unmet = append(unmet, fmt.Sprintf("%s: %s is %q, expected %q", obj.Attributes["dn"], attr, v, expected))
+			}
+		}
+	}
+	return strings.Join(unmet, ", ")
+}
+
+func waitConditions(d *schema.ResourceData) []waitCondition {
+	conditions := make([]waitCondition, 0)
+	for _, block := range d.Get("wait_for").([]interface{}) {
+		blockMap, ok := block.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		w := waitCondition{}
+		w.Dn, _ = blockMap["dn"].(string)
+		w.ClassName, _ = blockMap["class_name"].(string)
+		w.Filter, _ = blockMap["filter"].(string)
+		attributes, _ := blockMap["attributes"].(map[string]interface{})
+		w.Attributes = toStrMap(attributes)
+		timeout, _ := blockMap["timeout"].(int)
+		w.Timeout = time.Duration(timeout) * time.Second
+		if w.Dn == "" && w.ClassName == "" {
+			w.Dn = d.Get("dn").(string)
+		}
+		conditions = append(conditions, w)
+	}
+	return conditions
+}
+
+// waitFor polls the 'wait_for' conditions until they are met, they time out or the context is cancelled.
+func waitFor(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	for _, w := range waitConditions(d) {
+		path := w.path()
+		tflog.Debug(ctx, "Beginning wait", map[string]interface{}{"id": d.Id(), "path": path})
+		deadline := time.Now().Add(w.Timeout)
+		for {
+			objs, diags := queryObjects(ctx, meta, path)
+			if diags.HasError() {
+				return diags
+			}
+			unmet := w.unmetAttributes(objs, meta.(apiClient).NormalizationRules)
+			if unmet == "" {
+				break
+			}
+			remaining := time.Until(deadline)
+			if remaining <= 0 {
+				return diag.Errorf("Timeout waiting for %s: %s", path, unmet)
+			}
+			if remaining > WaitForInterval {
+				remaining = WaitForInterval
+			}
+			if err := sleepContext(ctx, remaining); err != nil {
+				return diag.Errorf("Cancelled waiting for %s: %s", path, err)
+			}
+		}
+		tflog.Debug(ctx, "Wait finished successfully", map[string]interface{}{"id": d.Id(), "path": path})
+	}
+	return nil
+}
@@ -0,0 +1,36 @@
+package provider
+
+import "testing"
+
+func TestWaitConditionPath(t *testing.T) {
+	cases := []struct {
+		w    waitCondition
+		path string
+	}{
+		{waitCondition{Dn: "topology/pod-1/node-101"}, "/api/mo/topology/pod-1/node-101.json"},
+		{waitCondition{ClassName: "fabricNode", Filter: `eq(fabricNode.id,"101")`}, "/api/class/fabricNode.json?query-target-filter=eq%28fabricNode.id%2C%22101%22%29"},
+		{waitCondition{Dn: "topology/pod-1", ClassName: "fabricNode"}, "/api/mo/topology/pod-1.json?query-target=subtree&target-subtree-class=fabricNode"},
+	}
+	for _, c := range cases {
+		if path := c.w.path(); path != c.path {
+			t.Fatalf("expected %s, got %s", c.path, path)
+		}
+	}
+}
+
+func TestWaitConditionUnmetAttributes(t *testing.T) {
+	w := waitCondition{Attributes: map[string]string{"fabricSt": "active"}}
+	if unmet := w.unmetAttributes(nil, nil); unmet == "" {
+		t.Fatalf("expected condition without objects to be unmet")
+	}
+	objs := []restObject{
+		{ClassName: "fabricNode", Attributes: map[string]string{"dn": "topology/pod-1/node-101", "fabricSt": "active"}},
+		{ClassName: "fabricNode", Attributes: map[string]string{"dn": "topology/pod-1/node-102", "fabricSt": "inactive"}},
+	}
+	if unmet := w.unmetAttributes(objs, nil); unmet != `topology/pod-1/node-102: fabricSt is "inactive", expected "active"` {
+		t.Fatalf("unexpected unmet attributes: %s", unmet)
+	}
+	if unmet := w.unmetAttributes(objs[:1], nil); unmet != "" {
+		t.Fatalf("unexpected unmet attributes: %s", unmet)
+	}
+}
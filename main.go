@@ -1,9 +1,9 @@
 package main
 
 import (
-	"context"
 	"flag"
 	"log"
+	"os"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/plugin"
 	"github.com/netascode/terraform-provider-aci/internal/provider"
@@ -29,19 +29,23 @@ var (
 )
 
 func main() {
+	// The "generate" subcommand renders configuration for existing objects instead of serving the provider
+	if len(os.Args) > 1 && os.Args[1] == "generate" {
+		if err := provider.Generate(os.Args[2:], os.Stdout); err != nil {
+			log.Fatal(err.Error())
+		}
+		return
+	}
+
 	var debugMode bool
 
 	flag.BoolVar(&debugMode, "debug", false, "set to true to run the provider with support for debuggers like delve")
 	flag.Parse()
 
-	opts := &plugin.ServeOpts{ProviderFunc: provider.New(version)}
-
-	if debugMode {
-		err := plugin.Debug(context.Background(), "registry.terraform.io/netascode/aci", opts)
-		if err != nil {
-			log.Fatal(err.Error())
-		}
-		return
+	opts := &plugin.ServeOpts{
+		Debug:        debugMode,
+		ProviderAddr: "registry.terraform.io/netascode/aci",
+		ProviderFunc: provider.New(version),
 	}
 
 	plugin.Serve(opts)